@@ -1,11 +1,13 @@
 package mapquest
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 	"net/http/httputil"
+	"strings"
 )
 
 const (
@@ -21,6 +23,10 @@ type Client struct {
 	https      bool
 	key        string
 	log        *log.Logger
+	retry      *RetryPolicy
+	limiter    *RateLimiter
+	limiters   map[Service]*RateLimiter
+	locale     string
 }
 
 // NewClient creates a new client for accessing the MapQuest API. You need
@@ -66,6 +72,59 @@ func (c *Client) SetLogger(logger *log.Logger) {
 	c.log = logger
 }
 
+// SetLocale sets the default language of the results, e.g. "de" or
+// "fr-FR". It can be overridden per request, where supported.
+// Set to an empty string to use the default of MapQuest (the default).
+func (c *Client) SetLocale(locale string) {
+	c.locale = locale
+}
+
+// Locale returns the default language of the results.
+func (c *Client) Locale() string {
+	return c.locale
+}
+
+// SetRetryPolicy sets the policy used to retry requests that failed
+// with a transient error. Set to nil to disable retries (the default).
+func (c *Client) SetRetryPolicy(policy *RetryPolicy) {
+	c.retry = policy
+}
+
+// RetryPolicy returns the registered retry policy, which may be nil.
+func (c *Client) RetryPolicy() *RetryPolicy {
+	return c.retry
+}
+
+// SetRateLimiter sets the rate limiter used for all requests, unless
+// a specific limiter for a service has been set with
+// SetServiceRateLimiter. Set to nil to disable rate limiting (the default).
+func (c *Client) SetRateLimiter(limiter *RateLimiter) {
+	c.limiter = limiter
+}
+
+// SetServiceRateLimiter sets the rate limiter used for all requests
+// to the given service, overriding the one set with SetRateLimiter.
+// Set to nil to remove the override.
+func (c *Client) SetServiceRateLimiter(service Service, limiter *RateLimiter) {
+	if limiter == nil {
+		delete(c.limiters, service)
+		return
+	}
+	if c.limiters == nil {
+		c.limiters = make(map[Service]*RateLimiter)
+	}
+	c.limiters[service] = limiter
+}
+
+// RateLimiter returns the rate limiter used for requests to the given
+// service. Notice that nil can be returned here.
+func (c *Client) RateLimiter(service Service) *RateLimiter {
+	if limiter, ok := c.limiters[service]; ok {
+		return limiter
+	}
+	return c.limiter
+}
+
 // BaseURL returns the base URL to access the MapQuest API.
 // Example: https://open.mapquestapi.com (without the trailing slash).
 func (c *Client) BaseURL() string {
@@ -93,8 +152,32 @@ func (c *Client) Nominatim() *NominatimAPI {
 	return &NominatimAPI{c: c}
 }
 
+// Directions gives access to the MapQuest directions API
+// described here: http://open.mapquestapi.com/directions/
+func (c *Client) Directions() *DirectionsAPI {
+	return &DirectionsAPI{c: c}
+}
+
+// Elevation gives access to the MapQuest elevation API
+// described here: http://open.mapquestapi.com/elevation/
+func (c *Client) Elevation() *ElevationAPI {
+	return &ElevationAPI{c: c}
+}
+
 // -- Helper functions --
 
+// acceptLanguage returns locale in the format used by
+// the Accept-Language HTTP header, e.g. "de-DE".
+func acceptLanguage(locale string) string {
+	return strings.Replace(locale, "_", "-", -1)
+}
+
+// mapquestLocale returns locale in the format used by
+// the MapQuest APIs, e.g. "de_DE".
+func mapquestLocale(locale string) string {
+	return strings.Replace(locale, "-", "_", -1)
+}
+
 func (c *Client) logRequest(r *http.Request) error {
 	if c.log != nil {
 		out, err := httputil.DumpRequestOut(r, true)
@@ -117,26 +200,73 @@ func (c *Client) logResponse(r *http.Response, body bool) error {
 	return nil
 }
 
-// getResponse returns the HTTP response to the caller.
-// Warning: The caller is responsible for closing the
-// Body via e.g. `defer res.Body.Close()`.
-func (c *Client) getResponse(url string) (*http.Response, error) {
-	req, err := http.NewRequest("GET", url, nil)
+// do performs a HTTP GET request to the specified URL and returns
+// the HTTP response. The request is bound to ctx, so cancelling ctx
+// aborts the request. Transient failures are retried according to
+// the retry policy of the client. The caller is responsible for
+// closing the Body. Each attempt is subject to the rate limiter
+// of the service.
+func (c *Client) do(ctx context.Context, service Service, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return nil, err
+		return nil, redactError(err)
 	}
 	req.Header.Set("User-Agent", UserAgent)
 
-	if err := c.logRequest(req); err != nil {
-		return nil, err
+	limiter := c.RateLimiter(service)
+
+	for attempt := 1; ; attempt++ {
+		if limiter != nil {
+			if err := limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := c.logRequest(req); err != nil {
+			return nil, err
+		}
+
+		res, err := c.httpClient.Do(req)
+		err = redactError(err)
+		if !c.retry.shouldRetry(attempt, req, res, err) {
+			return res, err
+		}
+
+		wait := c.retry.backoff(attempt, res)
+		if c.retry.OnRetry != nil {
+			c.retry.OnRetry(attempt, wait, res, err)
+		}
+		if c.log != nil {
+			if err != nil {
+				c.log.Printf("Retrying in %v after attempt %d failed: %v", wait, attempt, err)
+			} else {
+				c.log.Printf("Retrying in %v after attempt %d failed: %s", wait, attempt, res.Status)
+			}
+		}
+		drain(res)
+
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
 	}
+}
 
-	res, err := c.httpClient.Do(req)
+// getResponse returns the HTTP response to the caller.
+// Warning: The caller is responsible for closing the
+// Body via e.g. `defer res.Body.Close()`.
+func (c *Client) getResponse(ctx context.Context, service Service, url string) (*http.Response, error) {
+	res, err := c.do(ctx, service, url)
 	if err != nil {
 		return nil, err
 	}
 
 	if err := c.logResponse(res, false); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+
+	if err := checkResponse(res, url); err != nil {
+		res.Body.Close()
 		return nil, err
 	}
 
@@ -144,25 +274,20 @@ func (c *Client) getResponse(url string) (*http.Response, error) {
 }
 
 // getJSON performs a HTTP GET request to the specified URL,
-// decodes the result into v and returns nil.
-func (c *Client) getJSON(url string, v interface{}) error {
-	req, err := http.NewRequest("GET", url, nil)
+// decodes the result into v and returns nil. If MapQuest responds
+// with a non-2xx status code, an *APIError is returned.
+func (c *Client) getJSON(ctx context.Context, service Service, url string, v interface{}) error {
+	res, err := c.do(ctx, service, url)
 	if err != nil {
 		return err
 	}
-	req.Header.Set("User-Agent", UserAgent)
-
-	if err := c.logRequest(req); err != nil {
-		return err
-	}
+	defer res.Body.Close()
 
-	res, err := c.httpClient.Do(req)
-	if err != nil {
+	if err := c.logResponse(res, true); err != nil {
 		return err
 	}
-	defer res.Body.Close()
 
-	if err := c.logResponse(res, true); err != nil {
+	if err := checkResponse(res, url); err != nil {
 		return err
 	}
 
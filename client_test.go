@@ -1,9 +1,14 @@
 package mapquest
 
 import (
+	"context"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
+	"time"
 )
 
 func readKey(t *testing.T) (string, error) {
@@ -16,6 +21,31 @@ func readKey(t *testing.T) (string, error) {
 	return strings.TrimSpace(string(key)), nil
 }
 
+// newTestClient returns a client whose requests are all routed to
+// the given test server, regardless of the host in the URL.
+func newTestClient(t *testing.T, ts *httptest.Server) *Client {
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewClient("my-key")
+	c.SetHTTPClient(&http.Client{
+		Transport: rewriteTransport{u: u},
+	})
+	return c
+}
+
+type rewriteTransport struct {
+	u *url.URL
+}
+
+func (t rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.u.Scheme
+	req.URL.Host = t.u.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
 func TestDefaults(t *testing.T) {
 	expected := "open.mapquestapi.com"
 	if DefaultHost != expected {
@@ -53,3 +83,28 @@ func TestBaseURL(t *testing.T) {
 		t.Errorf("expeced base URL of %q, got: %q", expected, got)
 	}
 }
+
+func TestGetJSONContextCancel(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer ts.Close()
+
+	c := newTestClient(t, ts)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var v interface{}
+	err := c.getJSON(ctx, ServiceNominatim, c.BaseURL()+"/", &v)
+	if err == nil {
+		t.Fatal("expected error, got: nil")
+	}
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Errorf("expected context deadline to be exceeded, got: %v", ctx.Err())
+	}
+}
@@ -0,0 +1,658 @@
+package mapquest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+const (
+	// DirectionsPathPrefix is the default path prefix for the Directions API.
+	DirectionsPathPrefix = "/directions/v2"
+
+	// RouteMatrixAllToAllLimit is the maximum number of locations that
+	// MapQuest accepts in a single all-to-all route matrix request.
+	RouteMatrixAllToAllLimit = 25
+
+	// RouteMatrixOneToManyLimit is the maximum number of locations that
+	// MapQuest accepts in a single one-to-many route matrix request.
+	RouteMatrixOneToManyLimit = 100
+
+	// OptimizedRouteLimit is the maximum number of locations that
+	// MapQuest accepts in an optimized route request.
+	OptimizedRouteLimit = 25
+
+	// DefaultRouteMatrixConcurrency is the default number of route
+	// matrix requests that are sent to MapQuest concurrently.
+	DefaultRouteMatrixConcurrency = 4
+)
+
+// Route types supported by the Directions API.
+const (
+	RouteTypeFastest    = "fastest"
+	RouteTypeShortest   = "shortest"
+	RouteTypePedestrian = "pedestrian"
+	RouteTypeBicycle    = "bicycle"
+)
+
+// Road types that can be avoided by the Directions API.
+const (
+	AvoidLimitedAccess         = "Limited Access"
+	AvoidTollRoad              = "Toll Road"
+	AvoidFerry                 = "Ferry"
+	AvoidUnpaved               = "Unpaved"
+	AvoidSeasonalClosure       = "Approximate Seasonal Closure"
+	AvoidCountryBorderCrossing = "Country Border Crossing"
+)
+
+// Shape formats supported by the Directions API.
+const (
+	ShapeFormatRaw         = "raw"
+	ShapeFormatCompressed  = "cmp"
+	ShapeFormatCompressed6 = "cmp6"
+)
+
+// Units of distance supported by the Directions API.
+const (
+	UnitMiles      = "m"
+	UnitKilometers = "k"
+)
+
+// DirectionsAPI enables users to find routes between locations
+// via the MapQuest API.
+// See http://open.mapquestapi.com/directions/ for details.
+type DirectionsAPI struct {
+	c *Client
+}
+
+// Route returns the route between the locations of the request.
+func (api *DirectionsAPI) Route(req *DirectionsRouteRequest) (*DirectionsRouteResponse, error) {
+	return api.RouteContext(context.Background(), req)
+}
+
+// RouteContext is like Route, but binds the request to ctx.
+// Cancelling ctx aborts the request to MapQuest.
+func (api *DirectionsAPI) RouteContext(ctx context.Context, req *DirectionsRouteRequest) (*DirectionsRouteResponse, error) {
+	u, err := api.buildRouteURL(req)
+	if err != nil {
+		return nil, err
+	}
+	return api.getRoute(ctx, u, req.ShapeFormat)
+}
+
+// getRoute queries the MapQuest API with the given URL and returns
+// the decoded route. A compressed shape is decoded according to the
+// shape format of the request.
+func (api *DirectionsAPI) getRoute(ctx context.Context, u, shapeFormat string) (*DirectionsRouteResponse, error) {
+	res := new(DirectionsRouteResponse)
+	if err := api.c.getJSON(ctx, ServiceDirections, u, res); err != nil {
+		return nil, err
+	}
+	if err := res.Info.err(http.StatusOK, u); err != nil {
+		return nil, err
+	}
+	if res.Route != nil && res.Route.Shape != nil && res.Route.Shape.encoded != "" {
+		precision := ShapePrecisionCmp
+		if shapeFormat == ShapeFormatCompressed6 {
+			precision = ShapePrecisionCmp6
+		}
+		line, err := DecodeShape(res.Route.Shape.encoded, precision)
+		if err != nil {
+			return nil, err
+		}
+		res.Route.Shape.ShapePoints = line.Flat()
+		res.Route.Shape.encoded = ""
+	}
+
+	return res, nil
+}
+
+// OptimizedRoute returns the route between the locations of the request,
+// visiting them in the order that minimizes the overall time (or distance,
+// depending on the route type). The first and last locations stay in
+// place. The order in which the locations are visited is returned in
+// the LocationSequence of the route, i.e. LocationSequence[i] is the
+// index of the location of the request that is visited i-th.
+func (api *DirectionsAPI) OptimizedRoute(req *DirectionsRouteRequest) (*DirectionsRouteResponse, error) {
+	return api.OptimizedRouteContext(context.Background(), req)
+}
+
+// OptimizedRouteContext is like OptimizedRoute, but binds the request to ctx.
+// Cancelling ctx aborts the request to MapQuest.
+func (api *DirectionsAPI) OptimizedRouteContext(ctx context.Context, req *DirectionsRouteRequest) (*DirectionsRouteResponse, error) {
+	u, err := api.buildOptimizedRouteURL(req)
+	if err != nil {
+		return nil, err
+	}
+	return api.getRoute(ctx, u, req.ShapeFormat)
+}
+
+// RouteMatrix returns the distances and times between the locations
+// of the request, either from the first location to all others
+// (one-to-many, the default) or between all locations (all-to-all).
+// If the number of locations exceeds what MapQuest accepts in a single
+// request, the matrix is computed from many one-to-many requests.
+func (api *DirectionsAPI) RouteMatrix(req *DirectionsRouteMatrixRequest) (*DirectionsRouteMatrixResponse, error) {
+	return api.RouteMatrixContext(context.Background(), req)
+}
+
+// RouteMatrixContext is like RouteMatrix, but binds the requests to ctx.
+// Cancelling ctx aborts all outstanding requests to MapQuest.
+func (api *DirectionsAPI) RouteMatrixContext(ctx context.Context, req *DirectionsRouteMatrixRequest) (*DirectionsRouteMatrixResponse, error) {
+	n := len(req.Locations)
+	if n < 2 {
+		return nil, fmt.Errorf("mapquest: route matrix requires at least 2 locations, got %d", n)
+	}
+	locations, err := directionsLocations(req.Locations)
+	if err != nil {
+		return nil, err
+	}
+	options := api.options(&req.DirectionsOptions)
+
+	// Fits into a single request
+	if (req.AllToAll && n <= RouteMatrixAllToAllLimit) || (!req.AllToAll && n <= RouteMatrixOneToManyLimit) {
+		return api.routeMatrix(ctx, locations, req.AllToAll, options)
+	}
+
+	// Split into one-to-many requests per origin and chunk of destinations
+	origins := 1
+	if req.AllToAll {
+		origins = n
+	}
+	res := &DirectionsRouteMatrixResponse{
+		Distance:  make([][]float64, origins),
+		Time:      make([][]int, origins),
+		Locations: make([]*GeocodingAddressResponseLocation, n),
+	}
+	for i := range res.Distance {
+		res.Distance[i] = make([]float64, n)
+		res.Time[i] = make([]int, n)
+	}
+
+	concurrency := req.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultRouteMatrixConcurrency
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	sem := make(chan struct{}, concurrency)
+	chunkSize := RouteMatrixOneToManyLimit - 1
+	for i := 0; i < origins; i++ {
+		for start := 0; start < n; start += chunkSize {
+			end := start + chunkSize
+			if end > n {
+				end = n
+			}
+
+			wg.Add(1)
+			go func(i, start, end int) {
+				defer wg.Done()
+
+				select {
+				case sem <- struct{}{}:
+					defer func() { <-sem }()
+				case <-ctx.Done():
+					return
+				}
+
+				chunk := append([]interface{}{locations[i]}, locations[start:end]...)
+				m, err := api.routeMatrix(ctx, chunk, false, options)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+						cancel()
+					}
+					return
+				}
+				if len(m.Distance) != 1 || len(m.Distance[0]) != len(chunk) || len(m.Time[0]) != len(chunk) {
+					if firstErr == nil {
+						firstErr = fmt.Errorf("mapquest: unexpected size of route matrix")
+						cancel()
+					}
+					return
+				}
+				copy(res.Distance[i][start:end], m.Distance[0][1:])
+				copy(res.Time[i][start:end], m.Time[0][1:])
+				if len(m.Locations) == len(chunk) {
+					copy(res.Locations[start:end], m.Locations[1:])
+				}
+			}(i, start, end)
+		}
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return res, nil
+}
+
+// routeMatrix performs a single route matrix request.
+func (api *DirectionsAPI) routeMatrix(ctx context.Context, locations []interface{}, allToAll bool, options map[string]interface{}) (*DirectionsRouteMatrixResponse, error) {
+	opts := make(map[string]interface{}, len(options)+1)
+	for k, v := range options {
+		opts[k] = v
+	}
+	// One-to-many is the default
+	if allToAll {
+		opts["allToAll"] = true
+	}
+	u, err := api.buildURL("routematrix", map[string]interface{}{
+		"locations": locations,
+		"options":   opts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var raw struct {
+		Info      *Info                               `json:"info,omitempty"`
+		Distance  json.RawMessage                     `json:"distance,omitempty"`
+		Time      json.RawMessage                     `json:"time,omitempty"`
+		Locations []*GeocodingAddressResponseLocation `json:"locations,omitempty"`
+	}
+	if err := api.c.getJSON(ctx, ServiceDirections, u, &raw); err != nil {
+		return nil, err
+	}
+	if err := raw.Info.err(http.StatusOK, u); err != nil {
+		return nil, err
+	}
+
+	// All-to-all returns a matrix, one-to-many a single row
+	res := &DirectionsRouteMatrixResponse{Locations: raw.Locations}
+	if allToAll {
+		if err := json.Unmarshal(raw.Distance, &res.Distance); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(raw.Time, &res.Time); err != nil {
+			return nil, err
+		}
+	} else {
+		res.Distance = make([][]float64, 1)
+		res.Time = make([][]int, 1)
+		if err := json.Unmarshal(raw.Distance, &res.Distance[0]); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(raw.Time, &res.Time[0]); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// buildRouteURL returns the complete URL for the request,
+// including the key to query the MapQuest API.
+func (api *DirectionsAPI) buildRouteURL(req *DirectionsRouteRequest) (string, error) {
+	return api.buildRouteRequestURL("route", req)
+}
+
+// buildOptimizedRouteURL returns the complete URL for the request,
+// including the key to query the MapQuest API.
+func (api *DirectionsAPI) buildOptimizedRouteURL(req *DirectionsRouteRequest) (string, error) {
+	if len(req.Locations) > OptimizedRouteLimit {
+		return "", fmt.Errorf("mapquest: optimized route accepts at most %d locations, got %d",
+			OptimizedRouteLimit, len(req.Locations))
+	}
+	return api.buildRouteRequestURL("optimizedroute", req)
+}
+
+// buildRouteRequestURL returns the complete URL for a route request
+// to the given endpoint, including the key to query the MapQuest API.
+func (api *DirectionsAPI) buildRouteRequestURL(endpoint string, req *DirectionsRouteRequest) (string, error) {
+	if len(req.Locations) < 2 {
+		return "", fmt.Errorf("mapquest: route requires at least 2 locations, got %d", len(req.Locations))
+	}
+	locations, err := directionsLocations(req.Locations)
+	if err != nil {
+		return "", err
+	}
+
+	options := api.options(&req.DirectionsOptions)
+	if req.Shape {
+		switch req.ShapeFormat {
+		case "":
+			options["shapeFormat"] = ShapeFormatRaw
+		case ShapeFormatRaw, ShapeFormatCompressed, ShapeFormatCompressed6:
+			options["shapeFormat"] = req.ShapeFormat
+		default:
+			return "", fmt.Errorf("mapquest: invalid shape format %q", req.ShapeFormat)
+		}
+		options["fullShape"] = true
+	}
+
+	return api.buildURL(endpoint, map[string]interface{}{
+		"locations": locations,
+		"options":   options,
+	})
+}
+
+// options returns the options of a request in the format expected
+// by the MapQuest API, using the locale of the client by default.
+func (api *DirectionsAPI) options(o *DirectionsOptions) map[string]interface{} {
+	options := make(map[string]interface{})
+	if o.RouteType != "" {
+		options["routeType"] = o.RouteType
+	}
+	if len(o.Avoids) > 0 {
+		options["avoids"] = o.Avoids
+	}
+	if o.Unit != "" {
+		options["unit"] = o.Unit
+	}
+	locale := o.Locale
+	if locale == "" {
+		locale = api.c.locale
+	}
+	if locale != "" {
+		options["locale"] = mapquestLocale(locale)
+	}
+	return options
+}
+
+// buildURL returns the complete URL for the given endpoint,
+// passing v as JSON and including the key to query the MapQuest API.
+func (api *DirectionsAPI) buildURL(endpoint string, v interface{}) (string, error) {
+	urls := fmt.Sprintf("%s%s/%s", api.c.BaseURL(), DirectionsPathPrefix, endpoint)
+	u, err := url.Parse(urls)
+	if err != nil {
+		return "", err
+	}
+
+	jsonData, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	// Add key and other parameters to the query string
+	q := u.Query()
+	q.Set("inFormat", "json")
+	q.Set("json", string(jsonData))
+	q.Set("outFormat", "json")
+
+	// Key has to be handled specifically here, because
+	// the MapQuest API seems to not like the key URL-encoded
+	u.RawQuery = fmt.Sprintf("key=%s&%s", api.c.key, q.Encode())
+	return u.String(), nil
+}
+
+// directionsLocations converts locations into the format expected by
+// the MapQuest API. Each location must be a string, a GeoPoint, or a
+// GeocodingLocation (or a pointer to the latter two).
+func directionsLocations(locations []interface{}) ([]interface{}, error) {
+	result := make([]interface{}, len(locations))
+	for i, loc := range locations {
+		switch loc := loc.(type) {
+		case string:
+			result[i] = loc
+		case GeoPoint:
+			result[i] = map[string]interface{}{"latLng": newLatLng(loc)}
+		case *GeoPoint:
+			if loc == nil {
+				return nil, fmt.Errorf("mapquest: location %d is nil", i)
+			}
+			result[i] = map[string]interface{}{"latLng": newLatLng(*loc)}
+		case GeocodingLocation:
+			result[i] = loc
+		case *GeocodingLocation:
+			if loc == nil {
+				return nil, fmt.Errorf("mapquest: location %d is nil", i)
+			}
+			result[i] = *loc
+		default:
+			return nil, fmt.Errorf("mapquest: location %d is of unsupported type %T", i, loc)
+		}
+	}
+	return result, nil
+}
+
+// DirectionsOptions are the options common to all requests
+// of the Directions API.
+type DirectionsOptions struct {
+	// RouteType specifies how to route, e.g. RouteTypeFastest (the default),
+	// RouteTypeShortest, RouteTypePedestrian, or RouteTypeBicycle.
+	RouteType string
+
+	// Avoids lists the road types to avoid, e.g. AvoidTollRoad.
+	Avoids []string
+
+	// Unit of distances, i.e. UnitMiles (the default) or UnitKilometers.
+	Unit string
+
+	// Locale of the narrative, e.g. "de_DE". It overrides the
+	// locale of the client.
+	Locale string
+}
+
+// DirectionsRouteRequest is a request to find a route.
+type DirectionsRouteRequest struct {
+	// Locations to route between, in order. Each location is either
+	// a string (a single-line address), a GeoPoint, or a GeocodingLocation.
+	Locations []interface{}
+
+	DirectionsOptions
+
+	// Shape returns the shape points of the route.
+	Shape bool
+
+	// ShapeFormat is the format in which MapQuest transfers the shape,
+	// i.e. ShapeFormatRaw (the default), ShapeFormatCompressed, or
+	// ShapeFormatCompressed6. Compressed shapes are smaller, and are
+	// decoded transparently.
+	ShapeFormat string
+}
+
+// DirectionsRouteMatrixRequest is a request to compute the distances
+// and times between locations.
+type DirectionsRouteMatrixRequest struct {
+	// Locations to compute the matrix for. Each location is either
+	// a string (a single-line address), a GeoPoint, or a GeocodingLocation.
+	Locations []interface{}
+
+	// AllToAll computes the distances and times between all locations.
+	// By default, they are computed from the first location to all
+	// others (one-to-many).
+	AllToAll bool
+
+	DirectionsOptions
+
+	// Concurrency is the maximum number of requests sent to MapQuest
+	// concurrently, if the locations need to be split into many requests.
+	// It defaults to DefaultRouteMatrixConcurrency.
+	Concurrency int
+}
+
+// DirectionsRouteMatrixResponse is the response of a route matrix request.
+type DirectionsRouteMatrixResponse struct {
+	// Distance[i][j] is the distance from location i to location j,
+	// in the unit of the request. For one-to-many requests, there is
+	// only a single row, i.e. Distance[0][j] is the distance from the
+	// first location to location j.
+	Distance [][]float64
+
+	// Time[i][j] is the time in seconds from location i to location j.
+	// It has the same dimensions as Distance.
+	Time [][]int
+
+	// Locations are the geocoded locations of the request.
+	Locations []*GeocodingAddressResponseLocation
+}
+
+// DirectionsRouteResponse is the response of a route request.
+type DirectionsRouteResponse struct {
+	Info  *Info            `json:"info,omitempty"`
+	Route *DirectionsRoute `json:"route,omitempty"`
+}
+
+// DirectionsRoute is a route returned by the Directions API.
+type DirectionsRoute struct {
+	BoundingBox        *DirectionsBoundingBox              `json:"boundingBox,omitempty"`
+	Distance           float64                             `json:"distance,omitempty"`
+	FormattedTime      string                              `json:"formattedTime,omitempty"`
+	FuelUsed           float64                             `json:"fuelUsed,omitempty"`
+	HasCountryCross    bool                                `json:"hasCountryCross,omitempty"`
+	HasFerry           bool                                `json:"hasFerry,omitempty"`
+	HasHighway         bool                                `json:"hasHighway,omitempty"`
+	HasSeasonalClosure bool                                `json:"hasSeasonalClosure,omitempty"`
+	HasTollRoad        bool                                `json:"hasTollRoad,omitempty"`
+	HasUnpaved         bool                                `json:"hasUnpaved,omitempty"`
+	Legs               []*DirectionsLeg                    `json:"legs,omitempty"`
+	Locations          []*GeocodingAddressResponseLocation `json:"locations,omitempty"`
+	LocationSequence   []int                               `json:"locationSequence,omitempty"` // order of locations, see OptimizedRoute
+	RealTime           int                                 `json:"realTime,omitempty"`
+	SessionId          string                              `json:"sessionId,omitempty"`
+	Shape              *DirectionsShape                    `json:"shape,omitempty"`
+	Time               int                                 `json:"time,omitempty"`
+}
+
+// DirectionsBoundingBox is the bounding box of a route.
+type DirectionsBoundingBox struct {
+	UpperLeft  GeoPoint
+	LowerRight GeoPoint
+}
+
+type directionsBoundingBox struct {
+	UpperLeft  latLng `json:"ul"`
+	LowerRight latLng `json:"lr"`
+}
+
+// UnmarshalJSON decodes the bounding box, whose corners MapQuest
+// returns as lat/lng pairs.
+func (b *DirectionsBoundingBox) UnmarshalJSON(data []byte) error {
+	var v directionsBoundingBox
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	b.UpperLeft = v.UpperLeft.point()
+	b.LowerRight = v.LowerRight.point()
+	return nil
+}
+
+// MarshalJSON encodes the bounding box in the format returned by MapQuest.
+func (b DirectionsBoundingBox) MarshalJSON() ([]byte, error) {
+	return json.Marshal(directionsBoundingBox{
+		UpperLeft:  newLatLng(b.UpperLeft),
+		LowerRight: newLatLng(b.LowerRight),
+	})
+}
+
+// DirectionsLeg is the part of a route between two locations.
+type DirectionsLeg struct {
+	DestIndex     int                   `json:"destIndex,omitempty"`
+	Distance      float64               `json:"distance,omitempty"`
+	FormattedTime string                `json:"formattedTime,omitempty"`
+	Index         int                   `json:"index,omitempty"`
+	Maneuvers     []*DirectionsManeuver `json:"maneuvers,omitempty"`
+	OrigIndex     int                   `json:"origIndex,omitempty"`
+	Time          int                   `json:"time,omitempty"`
+}
+
+// DirectionsManeuver is a single step of a leg, e.g. a turn.
+type DirectionsManeuver struct {
+	Attributes    int       `json:"attributes,omitempty"`
+	Direction     int       `json:"direction,omitempty"`
+	DirectionName string    `json:"directionName,omitempty"`
+	Distance      float64   `json:"distance,omitempty"`
+	FormattedTime string    `json:"formattedTime,omitempty"`
+	IconUrl       string    `json:"iconUrl,omitempty"`
+	Index         int       `json:"index,omitempty"`
+	MapUrl        string    `json:"mapUrl,omitempty"`
+	Narrative     string    `json:"narrative,omitempty"`
+	StartPoint    *GeoPoint `json:"startPoint,omitempty"`
+	Streets       []string  `json:"streets,omitempty"`
+	Time          int       `json:"time,omitempty"`
+	TransportMode string    `json:"transportMode,omitempty"`
+	TurnType      int       `json:"turnType,omitempty"`
+}
+
+// UnmarshalJSON decodes the maneuver, including its start point,
+// which MapQuest returns as a lat/lng pair.
+func (m *DirectionsManeuver) UnmarshalJSON(data []byte) error {
+	type maneuver DirectionsManeuver // prevent recursion
+	v := struct {
+		*maneuver
+		StartPoint *latLng `json:"startPoint,omitempty"`
+	}{
+		maneuver: (*maneuver)(m),
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	m.StartPoint = nil
+	if v.StartPoint != nil {
+		pt := v.StartPoint.point()
+		m.StartPoint = &pt
+	}
+	return nil
+}
+
+// MarshalJSON encodes the maneuver in the format returned by MapQuest.
+func (m *DirectionsManeuver) MarshalJSON() ([]byte, error) {
+	type maneuver DirectionsManeuver // prevent recursion
+	v := struct {
+		*maneuver
+		StartPoint *latLng `json:"startPoint,omitempty"`
+	}{
+		maneuver: (*maneuver)(m),
+	}
+	if m.StartPoint != nil {
+		ll := newLatLng(*m.StartPoint)
+		v.StartPoint = &ll
+	}
+	return json.Marshal(v)
+}
+
+// DirectionsShape is the shape of a route.
+type DirectionsShape struct {
+	// LegIndexes are the indexes into the shape points where each leg starts.
+	LegIndexes []int `json:"legIndexes,omitempty"`
+
+	// ManeuverIndexes are the indexes into the shape points where
+	// each maneuver starts.
+	ManeuverIndexes []int `json:"maneuverIndexes,omitempty"`
+
+	// ShapePoints are the latitudes and longitudes of the shape,
+	// alternating. Use Points to get them as GeoPoints.
+	ShapePoints []float64 `json:"shapePoints,omitempty"`
+
+	encoded string // compressed shape points
+}
+
+// UnmarshalJSON decodes a shape returned by the Directions API. The
+// shape points are either a list of coordinates or a compressed string.
+func (s *DirectionsShape) UnmarshalJSON(data []byte) error {
+	type shape DirectionsShape // prevent recursion
+	v := struct {
+		*shape
+		ShapePoints json.RawMessage `json:"shapePoints,omitempty"`
+	}{
+		shape: (*shape)(s),
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	s.ShapePoints, s.encoded = nil, ""
+	if len(v.ShapePoints) == 0 || string(v.ShapePoints) == "null" {
+		return nil
+	}
+	if v.ShapePoints[0] == '"' {
+		return json.Unmarshal(v.ShapePoints, &s.encoded)
+	}
+	return json.Unmarshal(v.ShapePoints, &s.ShapePoints)
+}
+
+// Points returns the shape points as a GeoLine.
+func (s *DirectionsShape) Points() GeoLine {
+	line, _ := GeoLineFromFlat(s.ShapePoints[:len(s.ShapePoints)/2*2])
+	return line
+}
@@ -0,0 +1,359 @@
+package mapquest
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDirectionsBuildRouteURLs(t *testing.T) {
+	client := NewClient("my-key")
+	client.SetLocale("de-DE")
+
+	got, err := client.Directions().buildRouteURL(&DirectionsRouteRequest{
+		Locations: []interface{}{
+			"Clarendon Blvd, Arlington, VA",
+			GeoPoint{Latitude: 38.8951, Longitude: -77.0364},
+			&GeocodingLocation{City: "Baltimore", State: "MD"},
+		},
+		DirectionsOptions: DirectionsOptions{
+			RouteType: RouteTypeShortest,
+			Avoids:    []string{AvoidTollRoad},
+			Unit:      UnitKilometers,
+		},
+		Shape: true,
+	})
+	if err != nil {
+		t.Fatalf("expeced no error, got: %v", err)
+	}
+
+	u, err := url.Parse(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Path != "/directions/v2/route" {
+		t.Errorf("expected path %q, got: %q", "/directions/v2/route", u.Path)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(u.Query().Get("json")), &body); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"locations": []interface{}{
+			"Clarendon Blvd, Arlington, VA",
+			map[string]interface{}{"latLng": map[string]interface{}{"lat": 38.8951, "lng": -77.0364}},
+			map[string]interface{}{"city": "Baltimore", "state": "MD"},
+		},
+		"options": map[string]interface{}{
+			"routeType":   "shortest",
+			"avoids":      []interface{}{"Toll Road"},
+			"unit":        "k",
+			"locale":      "de_DE",
+			"shapeFormat": "raw",
+			"fullShape":   true,
+		},
+	}
+	if !reflect.DeepEqual(body, expected) {
+		t.Errorf("expected %v, got: %v", expected, body)
+	}
+
+	invalid := [][]interface{}{
+		nil,
+		{"Arlington, VA"},
+		{"Arlington, VA", 42},
+	}
+	for _, locations := range invalid {
+		if _, err := client.Directions().buildRouteURL(&DirectionsRouteRequest{Locations: locations}); err == nil {
+			t.Errorf("expected error for locations %v, got: nil", locations)
+		}
+	}
+}
+
+func TestDirectionsRoute(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"info": {"statuscode": 0, "messages": []},
+			"route": {
+				"distance": 2.9,
+				"time": 453,
+				"formattedTime": "00:07:33",
+				"boundingBox": {"ul": {"lat": 38.9, "lng": -77.1}, "lr": {"lat": 38.8, "lng": -77.0}},
+				"locationSequence": [0, 1],
+				"legs": [{
+					"index": 0,
+					"distance": 2.9,
+					"time": 453,
+					"maneuvers": [
+						{"index": 0, "narrative": "Start out going north.", "startPoint": {"lat": 38.8, "lng": -77.1}, "streets": ["Clarendon Blvd"]},
+						{"index": 1, "narrative": "Arrive.", "startPoint": {"lat": 38.9, "lng": -77.0}}
+					]
+				}],
+				"shape": {"shapePoints": [38.8, -77.1, 38.85, -77.05, 38.9, -77.0], "legIndexes": [0, 2]}
+			}
+		}`))
+	}))
+	defer ts.Close()
+
+	client := newTestClient(t, ts)
+	res, err := client.Directions().Route(&DirectionsRouteRequest{
+		Locations: []interface{}{"Arlington, VA", "Washington, DC"},
+		Shape:     true,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if res.Route == nil {
+		t.Fatal("expected route, got: nil")
+	}
+	if res.Route.Distance != 2.9 || res.Route.Time != 453 {
+		t.Errorf("expected distance 2.9 and time 453, got: %v and %v", res.Route.Distance, res.Route.Time)
+	}
+	if len(res.Route.Legs) != 1 || len(res.Route.Legs[0].Maneuvers) != 2 {
+		t.Fatalf("expected 1 leg with 2 maneuvers, got: %v", res.Route.Legs)
+	}
+	m := res.Route.Legs[0].Maneuvers[0]
+	if m.StartPoint == nil || *m.StartPoint != (GeoPoint{Latitude: 38.8, Longitude: -77.1}) {
+		t.Errorf("expected start point %v, got: %v", GeoPoint{Latitude: 38.8, Longitude: -77.1}, m.StartPoint)
+	}
+	if bb := res.Route.BoundingBox; bb == nil || bb.UpperLeft != (GeoPoint{Latitude: 38.9, Longitude: -77.1}) || bb.LowerRight != (GeoPoint{Latitude: 38.8, Longitude: -77.0}) {
+		t.Errorf("expected bounding box from 38.9,-77.1 to 38.8,-77.0, got: %v", bb)
+	}
+
+	// Routes must survive a round-trip, e.g. through a cache
+	data, err := json.Marshal(res.Route)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var route DirectionsRoute
+	if err := json.Unmarshal(data, &route); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !reflect.DeepEqual(route.BoundingBox, res.Route.BoundingBox) {
+		t.Errorf("expected bounding box %v, got: %v", res.Route.BoundingBox, route.BoundingBox)
+	}
+	if got := route.Legs[0].Maneuvers[0].StartPoint; got == nil || *got != *m.StartPoint {
+		t.Errorf("expected start point %v, got: %v", m.StartPoint, got)
+	}
+
+	points := res.Route.Shape.Points()
+	if len(points) != 3 || points[1] != (GeoPoint{Latitude: 38.85, Longitude: -77.05}) {
+		t.Errorf("expected 3 shape points, got: %v", points)
+	}
+}
+
+func TestDirectionsRouteError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"info": {"statuscode": 402, "messages": ["We are unable to route with the given locations."]}, "route": {}}`))
+	}))
+	defer ts.Close()
+
+	client := newTestClient(t, ts)
+	_, err := client.Directions().Route(&DirectionsRouteRequest{
+		Locations: []interface{}{"Arlington, VA", "Honolulu, HI"},
+	})
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("expected *APIError, got: %v", err)
+	}
+	if apiErr.Code != 402 {
+		t.Errorf("expected status code %d, got: %d", 402, apiErr.Code)
+	}
+}
+
+// routeMatrixHandler simulates the route matrix endpoint, using the
+// difference of the latitudes of two locations as their distance.
+func routeMatrixHandler(t *testing.T, calls *int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		var req struct {
+			Locations []struct {
+				LatLng latLng `json:"latLng"`
+			} `json:"locations"`
+			Options struct {
+				AllToAll bool `json:"allToAll"`
+			} `json:"options"`
+		}
+		if err := json.Unmarshal([]byte(r.URL.Query().Get("json")), &req); err != nil {
+			t.Error(err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		row := func(i int) ([]float64, []int) {
+			dist := make([]float64, len(req.Locations))
+			times := make([]int, len(req.Locations))
+			for j := range req.Locations {
+				dist[j] = math.Abs(req.Locations[j].LatLng.Latitude - req.Locations[i].LatLng.Latitude)
+				times[j] = int(dist[j]) * 60
+			}
+			return dist, times
+		}
+		res := map[string]interface{}{"info": map[string]interface{}{"statuscode": 0}}
+		if req.Options.AllToAll {
+			if len(req.Locations) > RouteMatrixAllToAllLimit {
+				t.Errorf("expected at most %d locations, got: %d", RouteMatrixAllToAllLimit, len(req.Locations))
+			}
+			var dist [][]float64
+			var times [][]int
+			for i := range req.Locations {
+				d, tm := row(i)
+				dist = append(dist, d)
+				times = append(times, tm)
+			}
+			res["distance"], res["time"] = dist, times
+		} else {
+			if len(req.Locations) > RouteMatrixOneToManyLimit {
+				t.Errorf("expected at most %d locations, got: %d", RouteMatrixOneToManyLimit, len(req.Locations))
+			}
+			res["distance"], res["time"] = row(0)
+		}
+		json.NewEncoder(w).Encode(res)
+	}
+}
+
+func TestDirectionsRouteMatrix(t *testing.T) {
+	tests := []struct {
+		Locations int
+		AllToAll  bool
+		Calls     int32
+	}{
+		{Locations: 5, AllToAll: false, Calls: 1},
+		{Locations: 5, AllToAll: true, Calls: 1},
+		{Locations: 150, AllToAll: false, Calls: 2},
+		{Locations: 30, AllToAll: true, Calls: 30},
+	}
+	for _, test := range tests {
+		var calls int32
+		ts := httptest.NewServer(routeMatrixHandler(t, &calls))
+
+		req := &DirectionsRouteMatrixRequest{AllToAll: test.AllToAll}
+		for i := 0; i < test.Locations; i++ {
+			req.Locations = append(req.Locations, GeoPoint{Latitude: float64(i)})
+		}
+
+		client := newTestClient(t, ts)
+		res, err := client.Directions().RouteMatrix(req)
+		ts.Close()
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if n := atomic.LoadInt32(&calls); n != test.Calls {
+			t.Errorf("%d locations, all-to-all=%v: expected %d requests, got: %d", test.Locations, test.AllToAll, test.Calls, n)
+		}
+		rows := 1
+		if test.AllToAll {
+			rows = test.Locations
+		}
+		if len(res.Distance) != rows || len(res.Time) != rows {
+			t.Fatalf("expected %d rows, got: %d and %d", rows, len(res.Distance), len(res.Time))
+		}
+		for i := 0; i < rows; i++ {
+			if len(res.Distance[i]) != test.Locations {
+				t.Fatalf("expected %d columns, got: %d", test.Locations, len(res.Distance[i]))
+			}
+			for j := 0; j < test.Locations; j++ {
+				if expected := math.Abs(float64(j - i)); res.Distance[i][j] != expected {
+					t.Fatalf("expected distance from %d to %d of %v, got: %v", i, j, expected, res.Distance[i][j])
+				}
+				if expected := int(math.Abs(float64(j-i))) * 60; res.Time[i][j] != expected {
+					t.Fatalf("expected time from %d to %d of %v, got: %v", i, j, expected, res.Time[i][j])
+				}
+			}
+		}
+	}
+}
+
+func TestDirectionsOptimizedRoute(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/directions/v2/optimizedroute" {
+			t.Errorf("expected path %q, got: %q", "/directions/v2/optimizedroute", r.URL.Path)
+		}
+		w.Write([]byte(`{
+			"info": {"statuscode": 0},
+			"route": {
+				"distance": 12.5,
+				"time": 1520,
+				"locationSequence": [0, 2, 1, 3],
+				"legs": [{"index": 0}, {"index": 1}, {"index": 2}]
+			}
+		}`))
+	}))
+	defer ts.Close()
+
+	client := newTestClient(t, ts)
+	res, err := client.Directions().OptimizedRoute(&DirectionsRouteRequest{
+		Locations: []interface{}{"Depot", "Stop A", "Stop B", "Depot"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	expected := []int{0, 2, 1, 3}
+	if !reflect.DeepEqual(res.Route.LocationSequence, expected) {
+		t.Errorf("expected location sequence %v, got: %v", expected, res.Route.LocationSequence)
+	}
+	if len(res.Route.Legs) != 3 {
+		t.Errorf("expected 3 legs, got: %d", len(res.Route.Legs))
+	}
+
+	req := &DirectionsRouteRequest{}
+	for i := 0; i <= OptimizedRouteLimit; i++ {
+		req.Locations = append(req.Locations, GeoPoint{Latitude: float64(i)})
+	}
+	if _, err := client.Directions().buildOptimizedRouteURL(req); err == nil {
+		t.Errorf("expected error for %d locations, got: nil", len(req.Locations))
+	}
+}
+
+func TestDirectionsRouteCompressedShape(t *testing.T) {
+	line := GeoLine{
+		{Latitude: 38.8, Longitude: -77.1},
+		{Latitude: 38.85, Longitude: -77.05},
+		{Latitude: 38.9, Longitude: -77.0},
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"info": map[string]interface{}{"statuscode": 0},
+			"route": map[string]interface{}{
+				"shape": map[string]interface{}{
+					"shapePoints": line.EncodeShape(ShapePrecisionCmp6),
+				},
+			},
+		})
+	}))
+	defer ts.Close()
+
+	client := newTestClient(t, ts)
+	res, err := client.Directions().Route(&DirectionsRouteRequest{
+		Locations:   []interface{}{"Arlington, VA", "Washington, DC"},
+		Shape:       true,
+		ShapeFormat: ShapeFormatCompressed6,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	points := res.Route.Shape.Points()
+	if len(points) != len(line) {
+		t.Fatalf("expected %d shape points, got: %v", len(line), points)
+	}
+	for i := range line {
+		if !closeTo(points[i], line[i], 1e-6) {
+			t.Errorf("expected point %d to be %v, got: %v", i, line[i], points[i])
+		}
+	}
+}
+
+func TestGeoPointJSON(t *testing.T) {
+	// GeoPoint is encoded with its field names; the wire format
+	// of MapQuest is handled by the APIs.
+	data, err := json.Marshal(GeoPoint{Latitude: 52.5, Longitude: 13.4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"Latitude":52.5,"Longitude":13.4}`; string(data) != want {
+		t.Errorf("expected %s, got: %s", want, data)
+	}
+}
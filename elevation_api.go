@@ -0,0 +1,184 @@
+package mapquest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ElevationPathPrefix is the default path prefix for the Elevation API.
+	ElevationPathPrefix = "/elevation/v1"
+
+	// ElevationRawLimit is the maximum number of points that are sent
+	// as a plain list of coordinates. Longer lists are sent in the
+	// compressed shape format to keep the URL short.
+	ElevationRawLimit = 50
+)
+
+// Units of height supported by the Elevation API.
+const (
+	ElevationUnitMeters = "m"
+	ElevationUnitFeet   = "f"
+)
+
+// ElevationAPI enables users to request elevation data for a set of
+// points via the MapQuest API.
+// See http://open.mapquestapi.com/elevation/ for details.
+type ElevationAPI struct {
+	c *Client
+}
+
+// Profile returns the elevation profile along the points of the request.
+func (api *ElevationAPI) Profile(req *ElevationProfileRequest) (*ElevationProfileResponse, error) {
+	return api.ProfileContext(context.Background(), req)
+}
+
+// ProfileContext is like Profile, but binds the request to ctx.
+// Cancelling ctx aborts the request to MapQuest.
+func (api *ElevationAPI) ProfileContext(ctx context.Context, req *ElevationProfileRequest) (*ElevationProfileResponse, error) {
+	u, err := api.buildProfileURL(req)
+	if err != nil {
+		return nil, err
+	}
+
+	res := new(ElevationProfileResponse)
+	if err := api.c.getJSON(ctx, ServiceElevation, u, res); err != nil {
+		return nil, err
+	}
+	if err := res.Info.err(http.StatusOK, u); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// ChartURL returns the URL of a chart image of the elevation profile
+// along the points of the request, e.g. to embed it into a web page.
+// Notice that the URL includes the key.
+func (api *ElevationAPI) ChartURL(req *ElevationChartRequest) (string, error) {
+	q, err := api.query(&req.ElevationProfileRequest)
+	if err != nil {
+		return "", err
+	}
+	if req.Width > 0 {
+		q.Set("width", strconv.Itoa(req.Width))
+	}
+	if req.Height > 0 {
+		q.Set("height", strconv.Itoa(req.Height))
+	}
+	return api.buildURL("chart", q)
+}
+
+// buildProfileURL returns the complete URL for the request,
+// including the key to query the MapQuest API.
+func (api *ElevationAPI) buildProfileURL(req *ElevationProfileRequest) (string, error) {
+	q, err := api.query(req)
+	if err != nil {
+		return "", err
+	}
+	q.Set("outFormat", "json")
+	return api.buildURL("profile", q)
+}
+
+// query returns the parameters of the request. Long lists of points
+// are sent in the compressed shape format.
+func (api *ElevationAPI) query(req *ElevationProfileRequest) (url.Values, error) {
+	q := make(url.Values)
+	q.Set("inFormat", "kvp")
+	switch {
+	case req.Shape != "" && len(req.Points) > 0:
+		return nil, fmt.Errorf("mapquest: specify either Points or Shape, not both")
+	case req.Shape != "":
+		switch req.ShapeFormat {
+		case ShapeFormatCompressed, ShapeFormatCompressed6:
+		default:
+			return nil, fmt.Errorf("mapquest: invalid shape format %q for an encoded shape", req.ShapeFormat)
+		}
+		q.Set("shapeFormat", req.ShapeFormat)
+		q.Set("latLngCollection", req.Shape)
+	case len(req.Points) > ElevationRawLimit:
+		q.Set("shapeFormat", ShapeFormatCompressed6)
+		q.Set("latLngCollection", req.Points.EncodeShape(ShapePrecisionCmp6))
+	case len(req.Points) > 0:
+		coords := make([]string, 0, 2*len(req.Points))
+		for _, pt := range req.Points {
+			coords = append(coords,
+				strconv.FormatFloat(pt.Latitude, 'f', -1, 64),
+				strconv.FormatFloat(pt.Longitude, 'f', -1, 64))
+		}
+		q.Set("shapeFormat", ShapeFormatRaw)
+		q.Set("latLngCollection", strings.Join(coords, ","))
+	default:
+		return nil, fmt.Errorf("mapquest: elevation requires at least one point")
+	}
+	if req.Unit != "" {
+		q.Set("unit", req.Unit)
+	}
+	return q, nil
+}
+
+// buildURL returns the complete URL for the given endpoint,
+// including the key to query the MapQuest API.
+func (api *ElevationAPI) buildURL(endpoint string, q url.Values) (string, error) {
+	urls := fmt.Sprintf("%s%s/%s", api.c.BaseURL(), ElevationPathPrefix, endpoint)
+	u, err := url.Parse(urls)
+	if err != nil {
+		return "", err
+	}
+
+	// Key has to be handled specifically here, because
+	// the MapQuest API seems to not like the key URL-encoded
+	u.RawQuery = fmt.Sprintf("key=%s&%s", api.c.key, q.Encode())
+	return u.String(), nil
+}
+
+// ElevationProfileRequest is a request for the elevation profile
+// along a list of points.
+type ElevationProfileRequest struct {
+	// Points to get the elevation for.
+	Points GeoLine
+
+	// Shape is a line encoded in MapQuest's compressed shape format.
+	// Use it instead of Points, together with ShapeFormat.
+	Shape string
+
+	// ShapeFormat is the format of Shape, i.e. ShapeFormatCompressed
+	// or ShapeFormatCompressed6.
+	ShapeFormat string
+
+	// Unit of heights and distances, i.e. ElevationUnitMeters (the
+	// default) or ElevationUnitFeet.
+	Unit string
+}
+
+// ElevationChartRequest is a request for a chart image of the
+// elevation profile along a list of points.
+type ElevationChartRequest struct {
+	ElevationProfileRequest
+
+	// Width of the chart in pixels.
+	Width int
+
+	// Height of the chart in pixels.
+	Height int
+}
+
+// ElevationProfileResponse is the response of a profile request.
+type ElevationProfileResponse struct {
+	Info             *Info                    `json:"info,omitempty"`
+	ElevationProfile []*ElevationProfilePoint `json:"elevationProfile,omitempty"`
+	ShapePoints      []float64                `json:"shapePoints,omitempty"`
+}
+
+// ElevationProfilePoint is a point of an elevation profile.
+type ElevationProfilePoint struct {
+	// Distance from the first point, in kilometers or miles.
+	Distance float64 `json:"distance"`
+
+	// Height above sea level, in meters or feet.
+	Height float64 `json:"height"`
+}
@@ -0,0 +1,109 @@
+package mapquest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestElevationBuildProfileURLs(t *testing.T) {
+	client := NewClient("my-key")
+
+	got, err := client.Elevation().buildProfileURL(&ElevationProfileRequest{
+		Points: GeoLine{
+			{Latitude: 39.74012, Longitude: -104.9849},
+			{Latitude: 39.7995, Longitude: -105.7237},
+		},
+		Unit: ElevationUnitFeet,
+	})
+	if err != nil {
+		t.Fatalf("expeced no error, got: %v", err)
+	}
+	expected := "http://open.mapquestapi.com/elevation/v1/profile?key=my-key&inFormat=kvp&latLngCollection=39.74012%2C-104.9849%2C39.7995%2C-105.7237&outFormat=json&shapeFormat=raw&unit=f"
+	if got != expected {
+		t.Errorf("expected %q, got: %q", expected, got)
+	}
+
+	// Long lists of points are compressed
+	var line GeoLine
+	for i := 0; i <= ElevationRawLimit; i++ {
+		line = append(line, GeoPoint{Latitude: 39.7 + float64(i)/1000, Longitude: -105.0})
+	}
+	got, err = client.Elevation().buildProfileURL(&ElevationProfileRequest{Points: line})
+	if err != nil {
+		t.Fatalf("expeced no error, got: %v", err)
+	}
+	u, err := url.Parse(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := u.Query().Get("shapeFormat"); got != ShapeFormatCompressed6 {
+		t.Errorf("expected shape format %q, got: %q", ShapeFormatCompressed6, got)
+	}
+	decoded, err := DecodeShape(u.Query().Get("latLngCollection"), ShapePrecisionCmp6)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(decoded) != len(line) {
+		t.Errorf("expected %d points, got: %d", len(line), len(decoded))
+	}
+
+	invalid := []*ElevationProfileRequest{
+		{},
+		{Shape: "abc"},
+		{Shape: "abc", ShapeFormat: ShapeFormatCompressed, Points: line},
+	}
+	for i, req := range invalid {
+		if _, err := client.Elevation().buildProfileURL(req); err == nil {
+			t.Errorf("case %d: expected error, got: nil", i)
+		}
+	}
+}
+
+func TestElevationChartURL(t *testing.T) {
+	client := NewClient("my-key")
+	got, err := client.Elevation().ChartURL(&ElevationChartRequest{
+		ElevationProfileRequest: ElevationProfileRequest{
+			Shape:       "o}_q_@~ycjy@",
+			ShapeFormat: ShapeFormatCompressed6,
+		},
+		Width:  425,
+		Height: 350,
+	})
+	if err != nil {
+		t.Fatalf("expeced no error, got: %v", err)
+	}
+	expected := "http://open.mapquestapi.com/elevation/v1/chart?key=my-key&height=350&inFormat=kvp&latLngCollection=o%7D_q_%40~ycjy%40&shapeFormat=cmp6&width=425"
+	if got != expected {
+		t.Errorf("expected %q, got: %q", expected, got)
+	}
+}
+
+func TestElevationProfile(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"info": {"statuscode": 0},
+			"shapePoints": [39.74012, -104.9849, 39.7995, -105.7237],
+			"elevationProfile": [{"distance": 0, "height": 1616}, {"distance": 63.4, "height": 2892}]
+		}`))
+	}))
+	defer ts.Close()
+
+	client := newTestClient(t, ts)
+	res, err := client.Elevation().Profile(&ElevationProfileRequest{
+		Points: GeoLine{
+			{Latitude: 39.74012, Longitude: -104.9849},
+			{Latitude: 39.7995, Longitude: -105.7237},
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(res.ElevationProfile) != 2 {
+		t.Fatalf("expected 2 points in profile, got: %d", len(res.ElevationProfile))
+	}
+	if p := res.ElevationProfile[1]; p.Distance != 63.4 || p.Height != 2892 {
+		t.Errorf("expected distance 63.4 and height 2892, got: %v and %v", p.Distance, p.Height)
+	}
+}
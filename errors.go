@@ -0,0 +1,156 @@
+package mapquest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Status codes returned by MapQuest in the info block of a response.
+// See http://open.mapquestapi.com/geocoding/status_codes.html for details.
+const (
+	StatusOK           = 0
+	StatusBadRequest   = 400
+	StatusKeyError     = 403
+	StatusUnknownError = 500
+)
+
+// APIError is returned by all APIs when MapQuest responds with an
+// error, either via the HTTP status code or via the status code in
+// the info block of the response. Use errors.As to inspect it.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+
+	// Code is the status code returned by MapQuest in the info block
+	// of the response, e.g. StatusBadRequest or StatusKeyError.
+	// It is zero if the response did not include an info block.
+	Code int
+
+	// Messages returned by MapQuest, if any.
+	Messages []string
+
+	// URL of the request. The key is redacted.
+	URL string
+}
+
+// Error returns a string representation of the error.
+func (e *APIError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "mapquest: HTTP status %d", e.StatusCode)
+	if e.Code != 0 {
+		fmt.Fprintf(&sb, ", status code %d", e.Code)
+	}
+	if len(e.Messages) > 0 {
+		fmt.Fprintf(&sb, ": %s", strings.Join(e.Messages, "; "))
+	}
+	if e.URL != "" {
+		fmt.Fprintf(&sb, " (%s)", e.URL)
+	}
+	return sb.String()
+}
+
+// IsBadRequest returns true if MapQuest rejected the request
+// because of invalid input.
+func (e *APIError) IsBadRequest() bool {
+	return e.Code == StatusBadRequest || e.StatusCode == http.StatusBadRequest
+}
+
+// IsKeyError returns true if MapQuest rejected the key,
+// e.g. because it is invalid or not authorized for the API.
+func (e *APIError) IsKeyError() bool {
+	if e.IsQuotaExceeded() {
+		return false
+	}
+	return e.Code == StatusKeyError ||
+		e.StatusCode == http.StatusUnauthorized ||
+		e.StatusCode == http.StatusForbidden
+}
+
+// IsQuotaExceeded returns true if the request was rejected because
+// the key exceeded its transaction limit.
+func (e *APIError) IsQuotaExceeded() bool {
+	if e.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	for _, msg := range e.Messages {
+		msg = strings.ToLower(msg)
+		if strings.Contains(msg, "transaction limit") || strings.Contains(msg, "quota") {
+			return true
+		}
+	}
+	return false
+}
+
+// Info is the info block returned by some MapQuest APIs,
+// e.g. the Geocoding API.
+type Info struct {
+	StatusCode int      `json:"statuscode"`
+	Messages   []string `json:"messages,omitempty"`
+	Copyright  *struct {
+		Text         string `json:"text,omitempty"`
+		ImageURL     string `json:"imageUrl,omitempty"`
+		ImageAltText string `json:"imageAltText,omitempty"`
+	} `json:"copyright,omitempty"`
+}
+
+// err returns an APIError if the info block reports an error,
+// or nil otherwise.
+func (info *Info) err(statusCode int, url string) error {
+	if info == nil || info.StatusCode == StatusOK {
+		return nil
+	}
+	return &APIError{
+		StatusCode: statusCode,
+		Code:       info.StatusCode,
+		Messages:   info.Messages,
+		URL:        redactURL(url),
+	}
+}
+
+var keyRegexp = regexp.MustCompile(`([?&]key=)[^&]*`)
+
+// redactURL removes the key from url.
+func redactURL(url string) string {
+	return keyRegexp.ReplaceAllString(url, "${1}REDACTED")
+}
+
+// redactError removes the key from the URL of transport errors,
+// which would otherwise end up in logs with every timeout.
+func redactError(err error) error {
+	if ue, ok := err.(*url.Error); ok {
+		return &url.Error{Op: ue.Op, URL: redactURL(ue.URL), Err: ue.Err}
+	}
+	return err
+}
+
+// checkResponse returns an APIError if res has a non-2xx status code,
+// or nil otherwise. It consumes the body in case of an error.
+func checkResponse(res *http.Response, url string) error {
+	if res.StatusCode >= 200 && res.StatusCode <= 299 {
+		return nil
+	}
+	apiErr := &APIError{
+		StatusCode: res.StatusCode,
+		URL:        redactURL(url),
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil || len(body) == 0 {
+		return apiErr
+	}
+	var v struct {
+		Info *Info `json:"info"`
+	}
+	if err := json.Unmarshal(body, &v); err == nil && v.Info != nil {
+		apiErr.Code = v.Info.StatusCode
+		apiErr.Messages = v.Info.Messages
+	} else if msg := strings.TrimSpace(string(body)); msg != "" && !strings.HasPrefix(msg, "<") {
+		// Plain text error message (skip HTML error pages)
+		apiErr.Messages = []string{msg}
+	}
+	return apiErr
+}
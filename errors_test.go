@@ -0,0 +1,132 @@
+package mapquest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRedactURL(t *testing.T) {
+	tests := []struct {
+		URL      string
+		Expected string
+	}{
+		{
+			URL:      "http://open.mapquestapi.com/geocoding/v1/address?key=Fmjad|lufd281r2q,72=o5&inFormat=json",
+			Expected: "http://open.mapquestapi.com/geocoding/v1/address?key=REDACTED&inFormat=json",
+		},
+		{
+			URL:      "http://open.mapquestapi.com/staticmap/v4/getmap?size=500,300&key=Fmjad|lufd281r2q",
+			Expected: "http://open.mapquestapi.com/staticmap/v4/getmap?size=500,300&key=REDACTED",
+		},
+		{
+			URL:      "http://open.mapquestapi.com/nominatim/v1/search.php?format=json&q=Berlin",
+			Expected: "http://open.mapquestapi.com/nominatim/v1/search.php?format=json&q=Berlin",
+		},
+	}
+	for _, test := range tests {
+		got := redactURL(test.URL)
+		if got != test.Expected {
+			t.Errorf("expected %q, got: %q", test.Expected, got)
+		}
+	}
+}
+
+func TestAPIErrorFromHTTPStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"info":{"statuscode":403,"messages":["The AppKey submitted with this request is invalid."]}}`))
+	}))
+	defer ts.Close()
+
+	c := newTestClient(t, ts)
+	_, err := c.Geocoding().Address(&GeocodingAddressRequest{
+		Location: &GeocodingLocation{City: "Lancaster"},
+	})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got: %v", err)
+	}
+	if apiErr.StatusCode != http.StatusForbidden {
+		t.Errorf("expected HTTP status %d, got: %d", http.StatusForbidden, apiErr.StatusCode)
+	}
+	if apiErr.Code != StatusKeyError {
+		t.Errorf("expected status code %d, got: %d", StatusKeyError, apiErr.Code)
+	}
+	if len(apiErr.Messages) != 1 {
+		t.Errorf("expected 1 message, got: %v", apiErr.Messages)
+	}
+	if !apiErr.IsKeyError() {
+		t.Error("expected key error")
+	}
+	if apiErr.IsQuotaExceeded() {
+		t.Error("expected no quota error")
+	}
+}
+
+func TestAPIErrorFromInfo(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"info":{"statuscode":400,"messages":["Illegal argument from request: Insufficient info for location"]},"results":[]}`))
+	}))
+	defer ts.Close()
+
+	c := newTestClient(t, ts)
+	_, err := c.Geocoding().Address(&GeocodingAddressRequest{})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got: %v", err)
+	}
+	if apiErr.StatusCode != http.StatusOK {
+		t.Errorf("expected HTTP status %d, got: %d", http.StatusOK, apiErr.StatusCode)
+	}
+	if !apiErr.IsBadRequest() {
+		t.Errorf("expected bad request, got: %v", apiErr)
+	}
+	expected := "http://open.mapquestapi.com/geocoding/v1/address?key=REDACTED&inFormat=json&json=%7B%7D&outFormat=json"
+	if apiErr.URL != expected {
+		t.Errorf("expected URL %q, got: %q", expected, apiErr.URL)
+	}
+}
+
+func TestAPIErrorFromStaticMap(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte("Too many requests"))
+	}))
+	defer ts.Close()
+
+	c := newTestClient(t, ts)
+	_, err := c.StaticMap().Get(&StaticMapRequest{Width: 100, Height: 100})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got: %v", err)
+	}
+	if !apiErr.IsQuotaExceeded() {
+		t.Errorf("expected quota error, got: %v", apiErr)
+	}
+	if len(apiErr.Messages) != 1 || apiErr.Messages[0] != "Too many requests" {
+		t.Errorf("expected message %q, got: %v", "Too many requests", apiErr.Messages)
+	}
+}
+
+func TestTransportErrorRedactsKey(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	c := newTestClient(t, ts)
+	c.SetRetryPolicy(nil)
+	ts.Close()
+
+	_, err := c.Geocoding().Address(&GeocodingAddressRequest{
+		Location: &GeocodingLocation{City: "Lancaster"},
+	})
+	if err == nil {
+		t.Fatal("expected error, got: nil")
+	}
+	if strings.Contains(err.Error(), "my-key") {
+		t.Errorf("expected key to be redacted, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "key=REDACTED") {
+		t.Errorf("expected redacted URL in error, got: %v", err)
+	}
+}
@@ -1,10 +1,15 @@
 package mapquest
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
+	"net/http"
 	"net/url"
+	"strconv"
+	"sync"
 )
 
 var _ = log.Print
@@ -12,6 +17,20 @@ var _ = log.Print
 const (
 	// GeocodingPathPrefix is the default path prefix for the Geocoding API.
 	GeocodingPathPrefix = "/geocoding/v1"
+
+	// GeocodingBatchSize is the maximum number of locations that
+	// MapQuest accepts in a single batch request.
+	GeocodingBatchSize = 100
+
+	// GeocodingBatchMaxURLLength is the maximum length of the URL of a
+	// single batch request. Batch sends fewer than GeocodingBatchSize
+	// locations in a request if the URL would exceed this length, as
+	// servers and proxies commonly reject URLs longer than 8KB.
+	GeocodingBatchMaxURLLength = 8000
+
+	// DefaultGeocodingBatchConcurrency is the default number of batch
+	// requests that are sent to MapQuest concurrently.
+	DefaultGeocodingBatchConcurrency = 4
 )
 
 // GeocodingAPI enables users to take an address and get the associated
@@ -21,31 +40,240 @@ type GeocodingAPI struct {
 	c *Client
 }
 
-// Address returns information about a specific address.
+// Address returns information about a specific address. If the
+// request specifies many Locations, the results of the response are
+// aligned to them, i.e. Results[i] is the result for Locations[i].
 func (api *GeocodingAPI) Address(req *GeocodingAddressRequest) (*GeocodingAddressResponse, error) {
+	return api.AddressContext(context.Background(), req)
+}
+
+// AddressContext is like Address, but binds the request to ctx.
+// Cancelling ctx aborts the request to MapQuest.
+func (api *GeocodingAPI) AddressContext(ctx context.Context, req *GeocodingAddressRequest) (*GeocodingAddressResponse, error) {
 	u, err := api.buildAddressURL(req)
 	if err != nil {
 		return nil, err
 	}
+	res, err := api.get(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	if n := len(req.Locations); n > 0 && len(res.Results) != n {
+		return nil, fmt.Errorf("mapquest: expected %d results, got %d", n, len(res.Results))
+	}
+	return res, nil
+}
 
-	res := new(GeocodingAddressResponse)
-	if err := api.c.getJSON(u, res); err != nil {
+// Reverse returns the address of a specific location.
+func (api *GeocodingAPI) Reverse(req *GeocodingReverseRequest) (*GeocodingAddressResponse, error) {
+	return api.ReverseContext(context.Background(), req)
+}
+
+// ReverseContext is like Reverse, but binds the request to ctx.
+// Cancelling ctx aborts the request to MapQuest.
+func (api *GeocodingAPI) ReverseContext(ctx context.Context, req *GeocodingReverseRequest) (*GeocodingAddressResponse, error) {
+	u, err := api.buildReverseURL(req)
+	if err != nil {
 		return nil, err
 	}
+	return api.get(ctx, u)
+}
+
+// Batch returns information about many addresses. The locations are
+// split into chunks of at most GeocodingBatchSize locations and
+// GeocodingBatchMaxURLLength bytes, which are sent to MapQuest
+// concurrently. The results of the response are aligned to the
+// locations of the request. If a chunk fails, the Err field of its
+// results is set; the other chunks are not affected.
+func (api *GeocodingAPI) Batch(req *GeocodingBatchRequest) (*GeocodingBatchResponse, error) {
+	return api.BatchContext(context.Background(), req)
+}
+
+// BatchContext is like Batch, but binds the requests to ctx.
+// Cancelling ctx aborts all outstanding requests to MapQuest.
+func (api *GeocodingAPI) BatchContext(ctx context.Context, req *GeocodingBatchRequest) (*GeocodingBatchResponse, error) {
+	for i, loc := range req.Locations {
+		if loc == nil {
+			return nil, fmt.Errorf("mapquest: location %d of batch is nil", i)
+		}
+	}
+
+	concurrency := req.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultGeocodingBatchConcurrency
+	}
+
+	ends, err := api.batchChunks(req.Locations, req.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &GeocodingBatchResponse{
+		Results: make([]*GeocodingBatchResult, len(req.Locations)),
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	start := 0
+	for _, end := range ends {
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				setBatchError(res.Results[start:end], ctx.Err())
+				return
+			}
+
+			results, err := api.batchChunk(ctx, req.Locations[start:end], req.Options)
+			if err != nil {
+				setBatchError(res.Results[start:end], err)
+				return
+			}
+			copy(res.Results[start:end], results)
+		}(start, end)
+		start = end
+	}
+	wg.Wait()
 
 	return res, nil
 }
 
+// batchChunks splits locations into chunks that fit into a single
+// batch request and returns the end index of each chunk. A location
+// that exceeds GeocodingBatchMaxURLLength on its own is sent alone.
+func (api *GeocodingAPI) batchChunks(locations []*GeocodingLocation, options *GeocodingOptions) ([]int, error) {
+	// The locations are encoded as a JSON array in the query string,
+	// so the length of the URL grows by the escaped length of each
+	// location plus an escaped comma.
+	u, err := api.buildBatchURL(nil, options)
+	if err != nil {
+		return nil, err
+	}
+	base := len(u)
+
+	var ends []int
+	size, n := base, 0
+	for i, loc := range locations {
+		data, err := json.Marshal(loc)
+		if err != nil {
+			return nil, err
+		}
+		l := len(url.QueryEscape(string(data)))
+		if n > 0 {
+			l += len(url.QueryEscape(","))
+			if n == GeocodingBatchSize || size+l > GeocodingBatchMaxURLLength {
+				ends = append(ends, i)
+				size, n = base, 0
+				l = len(url.QueryEscape(string(data)))
+			}
+		}
+		size += l
+		n++
+	}
+	if n > 0 {
+		ends = append(ends, len(locations))
+	}
+	return ends, nil
+}
+
+// buildBatchURL returns the URL of a batch request.
+func (api *GeocodingAPI) buildBatchURL(locations []*GeocodingLocation, options *GeocodingOptions) (string, error) {
+	if locations == nil {
+		locations = []*GeocodingLocation{}
+	}
+	return api.buildURL("batch", "", struct {
+		Locations []*GeocodingLocation `json:"locations"`
+		Options   *GeocodingOptions    `json:"options,omitempty"`
+	}{
+		Locations: locations,
+		Options:   options,
+	})
+}
+
+// batchChunk geocodes at most GeocodingBatchSize locations
+// in a single request.
+func (api *GeocodingAPI) batchChunk(ctx context.Context, locations []*GeocodingLocation, options *GeocodingOptions) ([]*GeocodingBatchResult, error) {
+	u, err := api.buildBatchURL(locations, options)
+	if err != nil {
+		return nil, err
+	}
+	res, err := api.get(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*GeocodingBatchResult, len(locations))
+	for i := range results {
+		if i < len(res.Results) && res.Results[i] != nil {
+			results[i] = &GeocodingBatchResult{
+				GeocodingAddressResponseResults: res.Results[i],
+			}
+		} else {
+			results[i] = &GeocodingBatchResult{
+				Err: fmt.Errorf("mapquest: no result for location in batch"),
+			}
+		}
+	}
+	return results, nil
+}
+
+func setBatchError(results []*GeocodingBatchResult, err error) {
+	for i := range results {
+		results[i] = &GeocodingBatchResult{Err: err}
+	}
+}
+
+// get queries the MapQuest API with the given URL and returns
+// the decoded response.
+func (api *GeocodingAPI) get(ctx context.Context, u string) (*GeocodingAddressResponse, error) {
+	res := new(GeocodingAddressResponse)
+	if err := api.c.getJSON(ctx, ServiceGeocoding, u, res); err != nil {
+		return nil, err
+	}
+	if err := res.Info.err(http.StatusOK, u); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
 // buildAddressURL returns the complete URL for the request,
 // including the key to query the MapQuest API.
 func (api *GeocodingAPI) buildAddressURL(req *GeocodingAddressRequest) (string, error) {
-	urls := fmt.Sprintf("%s%s/address", api.c.BaseURL(), GeocodingPathPrefix)
+	if req.Location != nil && len(req.Locations) > 0 {
+		return "", fmt.Errorf("mapquest: specify either Location or Locations, not both")
+	}
+	for i, loc := range req.Locations {
+		if loc == nil {
+			return "", fmt.Errorf("mapquest: location %d is nil", i)
+		}
+	}
+	return api.buildURL("address", req.Language, req)
+}
+
+// buildReverseURL returns the complete URL for the request,
+// including the key to query the MapQuest API.
+func (api *GeocodingAPI) buildReverseURL(req *GeocodingReverseRequest) (string, error) {
+	if req.Location == nil {
+		return "", fmt.Errorf("mapquest: reverse geocoding requires a location")
+	}
+	return api.buildURL("reverse", "", req)
+}
+
+// buildURL returns the complete URL for the given endpoint,
+// passing v as JSON and including the key to query the MapQuest API.
+// If locale is empty, the locale of the client is used.
+func (api *GeocodingAPI) buildURL(endpoint, locale string, v interface{}) (string, error) {
+	urls := fmt.Sprintf("%s%s/%s", api.c.BaseURL(), GeocodingPathPrefix, endpoint)
 	u, err := url.Parse(urls)
 	if err != nil {
 		return "", err
 	}
 
-	jsonData, err := json.Marshal(req)
+	jsonData, err := json.Marshal(v)
 	if err != nil {
 		return "", err
 	}
@@ -55,6 +283,12 @@ func (api *GeocodingAPI) buildAddressURL(req *GeocodingAddressRequest) (string,
 	q.Set("inFormat", "json")
 	q.Set("json", string(jsonData))
 	q.Set("outFormat", "json")
+	if locale == "" {
+		locale = api.c.locale
+	}
+	if locale != "" {
+		q.Set("locale", mapquestLocale(locale))
+	}
 
 	// Key has to be handled specifically here, because
 	// the MapQuest API seems to not like the key URL-encoded
@@ -63,10 +297,147 @@ func (api *GeocodingAPI) buildAddressURL(req *GeocodingAddressRequest) (string,
 }
 
 type GeocodingAddressRequest struct {
+	// Location to geocode.
 	Location *GeocodingLocation `json:"location,omitempty"`
+
+	// Locations to geocode in a single request. Use this instead of
+	// Location. For a large number of locations, use Batch instead.
+	Locations []*GeocodingLocation `json:"locations,omitempty"`
+
+	// Options for geocoding.
+	Options *GeocodingOptions `json:"options,omitempty"`
+
+	// Language is the preferred language of the results, e.g. "de"
+	// or "fr-FR". It overrides the locale of the client.
+	Language string `json:"-"`
+}
+
+// GeocodingOptions specifies options for geocoding requests.
+type GeocodingOptions struct {
+	// MaxResults limits the number of results per location.
+	// The default is -1, i.e. no limit.
+	MaxResults int
+
+	// ThumbMaps specifies whether to return a URL to a static map
+	// thumbnail for each location. MapQuest defaults to true, so
+	// set it to false explicitly to skip the thumbnails.
+	ThumbMaps *bool
+
+	// IgnoreLatLngInput ignores the LatLng of the location,
+	// if specified, and geocodes the address only.
+	IgnoreLatLngInput bool
+
+	// BoundingBox biases the results to the given area. Results
+	// outside of the area are not excluded, but ranked lower.
+	BoundingBox *GeoBox
+
+	// Delimiter is used to separate the fields of a single-line
+	// address, e.g. "|". The default is ",".
+	Delimiter string
+}
+
+// MarshalJSON serializes the options into the JSON format
+// expected by the MapQuest API.
+func (o *GeocodingOptions) MarshalJSON() ([]byte, error) {
+	type boundingBox struct {
+		UpperLeft  latLng `json:"ul"`
+		LowerRight latLng `json:"lr"`
+	}
+	v := struct {
+		MaxResults        int          `json:"maxResults,omitempty"`
+		ThumbMaps         *bool        `json:"thumbMaps,omitempty"`
+		IgnoreLatLngInput bool         `json:"ignoreLatLngInput,omitempty"`
+		BoundingBox       *boundingBox `json:"boundingBox,omitempty"`
+		Delimiter         string       `json:"delimiter,omitempty"`
+	}{
+		MaxResults:        o.MaxResults,
+		ThumbMaps:         o.ThumbMaps,
+		IgnoreLatLngInput: o.IgnoreLatLngInput,
+		Delimiter:         o.Delimiter,
+	}
+	if box := o.BoundingBox; box != nil {
+		// The corners of a GeoBox can be in any order,
+		// but MapQuest wants the upper left and lower right
+		v.BoundingBox = &boundingBox{
+			UpperLeft: latLng{
+				Latitude:  math.Max(box.A.Latitude, box.B.Latitude),
+				Longitude: math.Min(box.A.Longitude, box.B.Longitude),
+			},
+			LowerRight: latLng{
+				Latitude:  math.Min(box.A.Latitude, box.B.Latitude),
+				Longitude: math.Max(box.A.Longitude, box.B.Longitude),
+			},
+		}
+	}
+	return json.Marshal(v)
+}
+
+// GeocodingReverseRequest is a request to find the address
+// of a specific location.
+type GeocodingReverseRequest struct {
+	// Location to find the address for.
+	Location *GeoPoint
+
+	// IncludeRoadMetadata returns metadata about the road,
+	// e.g. the speed limit, with the result.
+	IncludeRoadMetadata bool
+
+	// IncludeNearestIntersection returns the nearest intersection
+	// with the result.
+	IncludeNearestIntersection bool
+}
+
+// MarshalJSON serializes the request into the JSON format
+// expected by the MapQuest API.
+func (req *GeocodingReverseRequest) MarshalJSON() ([]byte, error) {
+	v := struct {
+		Location struct {
+			LatLng latLng `json:"latLng"`
+		} `json:"location"`
+		IncludeRoadMetadata        bool `json:"includeRoadMetadata,omitempty"`
+		IncludeNearestIntersection bool `json:"includeNearestIntersection,omitempty"`
+	}{
+		IncludeRoadMetadata:        req.IncludeRoadMetadata,
+		IncludeNearestIntersection: req.IncludeNearestIntersection,
+	}
+	if req.Location != nil {
+		v.Location.LatLng = newLatLng(*req.Location)
+	}
+	return json.Marshal(v)
+}
+
+// GeocodingBatchRequest is a request to geocode many addresses.
+type GeocodingBatchRequest struct {
+	// Locations to geocode. There is no limit on the number of locations.
+	Locations []*GeocodingLocation
+
+	// Options for geocoding the locations.
+	Options *GeocodingOptions
+
+	// Concurrency is the maximum number of requests sent to MapQuest
+	// concurrently. It defaults to DefaultGeocodingBatchConcurrency.
+	Concurrency int
+}
+
+// GeocodingBatchResponse is the response of a batch request.
+type GeocodingBatchResponse struct {
+	// Results are aligned to the Locations of the request, i.e.
+	// Results[i] is the result for Locations[i].
+	Results []*GeocodingBatchResult
+}
+
+// GeocodingBatchResult is the result for a single location
+// of a batch request.
+type GeocodingBatchResult struct {
+	*GeocodingAddressResponseResults
+
+	// Err is set if geocoding the location failed.
+	// In that case, GeocodingAddressResponseResults is nil.
+	Err error
 }
 
 type GeocodingAddressResponse struct {
+	Info    *Info `json:"info,omitempty"`
 	Options struct {
 		IgnoreLatLngInput bool `json:"ignoreLatLngInput,omitempty"`
 		MaxResults        int  `json:"maxResults,omitempty"`
@@ -96,21 +467,39 @@ type GeocodingAddressResponseLocation struct {
 		Longitude float64 `json:"lng,omitempty"`
 	} `json:"displayLatLng,omitempty"`
 	DragPoint          *bool  `json:"dragPoint,omitempty"`
-	GeocodeQuality     string `json:"geocodeQualtity,omitempty"`
-	GeocodeQualityCode string `json:"geocodeQualtityCode,omitempty"`
+	GeocodeQuality     string `json:"geocodeQuality,omitempty"`
+	GeocodeQualityCode string `json:"geocodeQualityCode,omitempty"`
 	LatLng             *struct {
 		Latitude  float64 `json:"lat,omitempty"`
 		Longitude float64 `json:"lng,omitempty"`
 	} `json:"latLng,omitempty"`
-	LinkId       int    `json:"linkId,omitempty"`
-	MapUrl       string `json:"mapUrl,omitempty"`
+	LinkId              int    `json:"linkId,omitempty"`
+	MapUrl              string `json:"mapUrl,omitempty"`
+	NearestIntersection *struct {
+		StreetDisplayName string `json:"streetDisplayName,omitempty"`
+		DistanceMeter     string `json:"distanceMeter,omitempty"`
+		LatLng            *struct {
+			Latitude  float64 `json:"latitude,omitempty"`
+			Longitude float64 `json:"longitude,omitempty"`
+		} `json:"latLng,omitempty"`
+		Label string `json:"label,omitempty"`
+	} `json:"nearestIntersection,omitempty"`
 	PostalCode   string `json:"postalCode,omitempty"`
+	RoadMetadata *struct {
+		SpeedLimitUnits string      `json:"speedLimitUnits,omitempty"`
+		SpeedLimit      int         `json:"speedLimit,omitempty"`
+		TollRoad        interface{} `json:"tollRoad,omitempty"`
+	} `json:"roadMetadata,omitempty"`
 	SideOfStreet string `json:"sideOfStreet,omitempty"`
 	Street       string `json:"street,omitempty"`
 	Type         string `json:"type,omitempty"`
 }
 
+// GeocodingLocation is a location to geocode. It is either specified
+// as a single-line address in SingleLine, e.g. "1090 N Charlotte St,
+// Lancaster, PA", or split into its components, e.g. Street and City.
 type GeocodingLocation struct {
+	SingleLine string `json:"location,omitempty"`
 	LatLng     string `json:"latLng,omitempty"`
 	Street     string `json:"street,omitempty"`
 	City       string `json:"city,omitempty"`
@@ -121,3 +510,50 @@ type GeocodingLocation struct {
 	Type       string `json:"type,omitempty"`
 	DragPoint  *bool  `json:"dragPoint,omitempty"`
 }
+
+// MarshalJSON serializes the location into the JSON format expected
+// by the MapQuest API. A location that only specifies SingleLine is
+// serialized as a string.
+func (loc GeocodingLocation) MarshalJSON() ([]byte, error) {
+	type location GeocodingLocation // prevent recursion
+	if loc.SingleLine != "" && loc == (GeocodingLocation{SingleLine: loc.SingleLine}) {
+		return json.Marshal(loc.SingleLine)
+	}
+	return json.Marshal(location(loc))
+}
+
+// UnmarshalJSON decodes a location as returned by MapQuest, e.g. as
+// the provided location of a result. It accepts a single-line address
+// as well as a latLng given as a string or as a lat/lng object.
+func (loc *GeocodingLocation) UnmarshalJSON(data []byte) error {
+	var singleLine string
+	if err := json.Unmarshal(data, &singleLine); err == nil {
+		*loc = GeocodingLocation{SingleLine: singleLine}
+		return nil
+	}
+
+	type location GeocodingLocation // prevent recursion
+	v := struct {
+		*location
+		LatLng json.RawMessage `json:"latLng,omitempty"`
+	}{
+		location: (*location)(loc),
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	loc.LatLng = ""
+	if len(v.LatLng) == 0 || string(v.LatLng) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(v.LatLng, &loc.LatLng); err == nil {
+		return nil
+	}
+	var ll latLng
+	if err := json.Unmarshal(v.LatLng, &ll); err != nil {
+		return fmt.Errorf("mapquest: invalid latLng %s: %v", v.LatLng, err)
+	}
+	pt := ll.point()
+	loc.LatLng = strconv.FormatFloat(pt.Latitude, 'f', -1, 64) + "," + strconv.FormatFloat(pt.Longitude, 'f', -1, 64)
+	return nil
+}
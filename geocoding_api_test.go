@@ -2,7 +2,12 @@ package mapquest
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
 	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 )
 
@@ -28,6 +33,39 @@ func TestGeocodingBuildAddressURLs(t *testing.T) {
 			},
 			URL: "http://open.mapquestapi.com/geocoding/v1/address?key=" + testKey + "&inFormat=json&json=%7B%22location%22%3A%7B%22street%22%3A%221090+N+Charlotte+St%22%2C%22city%22%3A%22Lancaster%22%2C%22state%22%3A%22PA%22%2C%22postalCode%22%3A%2217603%22%7D%7D&outFormat=json",
 		},
+		{
+			Request: &GeocodingAddressRequest{
+				Location: &GeocodingLocation{
+					SingleLine: "1090 N Charlotte St, Lancaster, PA",
+				},
+			},
+			URL: "http://open.mapquestapi.com/geocoding/v1/address?key=" + testKey + "&inFormat=json&json=%7B%22location%22%3A%221090+N+Charlotte+St%2C+Lancaster%2C+PA%22%7D&outFormat=json",
+		},
+		{
+			Request: &GeocodingAddressRequest{
+				Locations: []*GeocodingLocation{
+					{SingleLine: "Lancaster, PA"},
+					{City: "York", State: "PA"},
+				},
+			},
+			URL: "http://open.mapquestapi.com/geocoding/v1/address?key=" + testKey + "&inFormat=json&json=%7B%22locations%22%3A%5B%22Lancaster%2C+PA%22%2C%7B%22city%22%3A%22York%22%2C%22state%22%3A%22PA%22%7D%5D%7D&outFormat=json",
+		},
+		{
+			Request: &GeocodingAddressRequest{
+				Location: &GeocodingLocation{
+					City: "Lancaster",
+				},
+				Options: &GeocodingOptions{
+					MaxResults: 5,
+					ThumbMaps:  new(bool),
+					BoundingBox: &GeoBox{
+						A: GeoPoint{Latitude: 39.5, Longitude: -75.5},
+						B: GeoPoint{Latitude: 40.5, Longitude: -77.5},
+					},
+				},
+			},
+			URL: "http://open.mapquestapi.com/geocoding/v1/address?key=" + testKey + "&inFormat=json&json=%7B%22location%22%3A%7B%22city%22%3A%22Lancaster%22%7D%2C%22options%22%3A%7B%22maxResults%22%3A5%2C%22thumbMaps%22%3Afalse%2C%22boundingBox%22%3A%7B%22ul%22%3A%7B%22lat%22%3A40.5%2C%22lng%22%3A-77.5%7D%2C%22lr%22%3A%7B%22lat%22%3A39.5%2C%22lng%22%3A-75.5%7D%7D%7D%7D&outFormat=json",
+		},
 	}
 
 	client := NewClient(testKey)
@@ -40,6 +78,41 @@ func TestGeocodingBuildAddressURLs(t *testing.T) {
 			t.Errorf("expected %q, got: %q", test.URL, got)
 		}
 	}
+
+	_, err = client.Geocoding().buildAddressURL(&GeocodingAddressRequest{
+		Location:  &GeocodingLocation{City: "Lancaster"},
+		Locations: []*GeocodingLocation{{City: "York"}},
+	})
+	if err == nil {
+		t.Error("expected error for both Location and Locations, got: nil")
+	}
+}
+
+func TestGeocodingLanguage(t *testing.T) {
+	client := NewClient("my-key")
+	client.SetLocale("fr-FR")
+
+	req := &GeocodingAddressRequest{
+		Location: &GeocodingLocation{City: "Paris"},
+	}
+	got, err := client.Geocoding().buildAddressURL(req)
+	if err != nil {
+		t.Fatalf("expeced no error, got: %v", err)
+	}
+	expected := "http://open.mapquestapi.com/geocoding/v1/address?key=my-key&inFormat=json&json=%7B%22location%22%3A%7B%22city%22%3A%22Paris%22%7D%7D&locale=fr_FR&outFormat=json"
+	if got != expected {
+		t.Errorf("expected %q, got: %q", expected, got)
+	}
+
+	req.Language = "de-DE"
+	got, err = client.Geocoding().buildAddressURL(req)
+	if err != nil {
+		t.Fatalf("expeced no error, got: %v", err)
+	}
+	expected = "http://open.mapquestapi.com/geocoding/v1/address?key=my-key&inFormat=json&json=%7B%22location%22%3A%7B%22city%22%3A%22Paris%22%7D%7D&locale=de_DE&outFormat=json"
+	if got != expected {
+		t.Errorf("expected %q, got: %q", expected, got)
+	}
 }
 
 func TestGeocodingAddress(t *testing.T) {
@@ -144,3 +217,246 @@ func TestGeocodingAddress(t *testing.T) {
 		t.Errorf("expected Type %q, got: %q", "s", location.Type)
 	}
 }
+
+func TestGeocodingBuildReverseURLs(t *testing.T) {
+	testKey, err := readKey(t)
+	if err != nil {
+		t.Fail()
+		return
+	}
+
+	tests := []struct {
+		Request *GeocodingReverseRequest
+		URL     string
+	}{
+		{
+			Request: &GeocodingReverseRequest{
+				Location: &GeoPoint{
+					Latitude:  40.053116,
+					Longitude: -76.313603,
+				},
+				IncludeRoadMetadata: true,
+			},
+			URL: "http://open.mapquestapi.com/geocoding/v1/reverse?key=" + testKey + "&inFormat=json&json=%7B%22location%22%3A%7B%22latLng%22%3A%7B%22lat%22%3A40.053116%2C%22lng%22%3A-76.313603%7D%7D%2C%22includeRoadMetadata%22%3Atrue%7D&outFormat=json",
+		},
+	}
+
+	client := NewClient(testKey)
+	for _, test := range tests {
+		got, err := client.Geocoding().buildReverseURL(test.Request)
+		if err != nil {
+			t.Fatalf("expeced no error, got: %v", err)
+		}
+		if got != test.URL {
+			t.Errorf("expected %q, got: %q", test.URL, got)
+		}
+	}
+
+	if _, err := client.Geocoding().buildReverseURL(&GeocodingReverseRequest{}); err == nil {
+		t.Error("expected error for missing location, got: nil")
+	}
+}
+
+func TestGeocodingReverse(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/geocoding/v1/reverse" {
+			t.Errorf("expected path %q, got: %q", "/geocoding/v1/reverse", r.URL.Path)
+		}
+		w.Write([]byte(`{
+			"info": {
+				"statuscode": 0,
+				"copyright": {"text": "© 2016 MapQuest, Inc.", "imageUrl": "http://api.mqcdn.com/res/mqlogo.gif", "imageAltText": "© 2016 MapQuest, Inc."},
+				"messages": []
+			},
+			"options": {"maxResults": 1, "thumbMaps": true, "ignoreLatLngInput": false},
+			"results": [{
+				"providedLocation": {"latLng": {"lat": 40.053116, "lng": -76.313603}},
+				"locations": [{
+					"street": "100 N Queen St",
+					"adminArea6": "",
+					"adminArea6Type": "Neighborhood",
+					"adminArea5": "Lancaster",
+					"adminArea5Type": "City",
+					"adminArea4": "Lancaster",
+					"adminArea4Type": "County",
+					"adminArea3": "PA",
+					"adminArea3Type": "State",
+					"adminArea1": "US",
+					"adminArea1Type": "Country",
+					"postalCode": "17603",
+					"geocodeQualityCode": "P1AAA",
+					"geocodeQuality": "POINT",
+					"dragPoint": false,
+					"sideOfStreet": "R",
+					"linkId": 0,
+					"unknownInput": "",
+					"type": "s",
+					"latLng": {"lat": 40.053116, "lng": -76.313603},
+					"displayLatLng": {"lat": 40.053116, "lng": -76.313603},
+					"mapUrl": "http://open.mapquestapi.com/staticmap/v4/getmap?key=KEY&type=map&size=225,160&pois=purple-1,40.053116,-76.313603,0,0,|&center=40.053116,-76.313603&zoom=15&rand=-1"
+				}]
+			}]
+		}`))
+	}))
+	defer ts.Close()
+
+	client := newTestClient(t, ts)
+	res, err := client.Geocoding().Reverse(&GeocodingReverseRequest{
+		Location: &GeoPoint{Latitude: 40.053116, Longitude: -76.313603},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(res.Results) != 1 {
+		t.Fatalf("expected %d result, got: %d", 1, len(res.Results))
+	}
+	result := res.Results[0]
+	if result.ProvidedLocation == nil || result.ProvidedLocation.LatLng != "40.053116,-76.313603" {
+		t.Errorf("expected provided location %q, got: %+v", "40.053116,-76.313603", result.ProvidedLocation)
+	}
+	if len(result.Locations) != 1 {
+		t.Fatalf("expected %d location, got: %d", 1, len(result.Locations))
+	}
+	if loc := result.Locations[0]; loc.Street != "100 N Queen St" || loc.PostalCode != "17603" {
+		t.Errorf("expected %q in %q, got: %q in %q", "100 N Queen St", "17603", loc.Street, loc.PostalCode)
+	}
+}
+
+func TestGeocodingLocationDecoding(t *testing.T) {
+	tests := []struct {
+		JSON     string
+		Expected GeocodingLocation
+	}{
+		{`"Lancaster, PA"`, GeocodingLocation{SingleLine: "Lancaster, PA"}},
+		{`{"street":"100 N Queen St","city":"Lancaster"}`, GeocodingLocation{Street: "100 N Queen St", City: "Lancaster"}},
+		{`{"latLng":"40.05,-76.31"}`, GeocodingLocation{LatLng: "40.05,-76.31"}},
+		{`{"latLng":{"lat":40.05,"lng":-76.31}}`, GeocodingLocation{LatLng: "40.05,-76.31"}},
+	}
+	for _, test := range tests {
+		var loc GeocodingLocation
+		if err := json.Unmarshal([]byte(test.JSON), &loc); err != nil {
+			t.Fatalf("%s: expected no error, got: %v", test.JSON, err)
+		}
+		if loc != test.Expected {
+			t.Errorf("%s: expected %+v, got: %+v", test.JSON, test.Expected, loc)
+		}
+	}
+}
+
+func TestGeocodingBatch(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.URL.Path != "/geocoding/v1/batch" {
+			t.Errorf("expected path %q, got: %q", "/geocoding/v1/batch", r.URL.Path)
+		}
+		var req struct {
+			Locations []*GeocodingLocation `json:"locations"`
+		}
+		if err := json.Unmarshal([]byte(r.URL.Query().Get("json")), &req); err != nil {
+			t.Error(err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if len(req.Locations) > GeocodingBatchSize {
+			t.Errorf("expected at most %d locations, got: %d", GeocodingBatchSize, len(req.Locations))
+		}
+		if req.Locations[0].Street == "fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		res := new(GeocodingAddressResponse)
+		for _, loc := range req.Locations {
+			res.Results = append(res.Results, &GeocodingAddressResponseResults{
+				ProvidedLocation: loc,
+			})
+		}
+		json.NewEncoder(w).Encode(res)
+	}))
+	defer ts.Close()
+
+	req := &GeocodingBatchRequest{Concurrency: 2}
+	for i := 0; i < 250; i++ {
+		req.Locations = append(req.Locations, &GeocodingLocation{
+			Street: fmt.Sprintf("%d Main St", i),
+		})
+	}
+	req.Locations[100].Street = "fail"
+
+	client := newTestClient(t, ts)
+	res, err := client.Geocoding().Batch(req)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 3 {
+		t.Errorf("expected %d requests, got: %d", 3, n)
+	}
+	if len(res.Results) != len(req.Locations) {
+		t.Fatalf("expected %d results, got: %d", len(req.Locations), len(res.Results))
+	}
+	for i, result := range res.Results {
+		if i >= 100 && i < 200 {
+			if result.Err == nil {
+				t.Errorf("expected error for result %d, got: nil", i)
+			}
+			continue
+		}
+		if result.Err != nil {
+			t.Fatalf("expected no error for result %d, got: %v", i, result.Err)
+		}
+		if result.ProvidedLocation.Street != req.Locations[i].Street {
+			t.Errorf("expected result %d for %q, got: %q", i, req.Locations[i].Street, result.ProvidedLocation.Street)
+		}
+	}
+}
+
+func TestGeocodingBatchURLLength(t *testing.T) {
+	var locations []*GeocodingLocation
+	for i := 0; i < 250; i++ {
+		locations = append(locations, &GeocodingLocation{
+			Street:     fmt.Sprintf("%d Pennsylvania Avenue Northwest, Suite %d", 1600+i, i),
+			City:       "Washington",
+			County:     "District of Columbia",
+			State:      "DC",
+			Country:    "United States of America",
+			PostalCode: fmt.Sprintf("2%04d", i),
+		})
+	}
+	options := &GeocodingOptions{MaxResults: 1}
+
+	client := NewClient("my-key")
+	ends, err := client.Geocoding().batchChunks(locations, options)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(ends) <= 3 {
+		t.Errorf("expected more than %d chunks for long addresses, got: %d", 3, len(ends))
+	}
+	start := 0
+	for _, end := range ends {
+		if n := end - start; n <= 0 || n > GeocodingBatchSize {
+			t.Fatalf("expected 1 to %d locations in chunk, got: %d", GeocodingBatchSize, n)
+		}
+		u, err := client.Geocoding().buildBatchURL(locations[start:end], options)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if len(u) > GeocodingBatchMaxURLLength {
+			t.Errorf("expected URL of at most %d bytes, got: %d", GeocodingBatchMaxURLLength, len(u))
+		}
+		if end < len(locations) {
+			// The chunk must be as large as possible
+			u, err := client.Geocoding().buildBatchURL(locations[start:end+1], options)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if end-start < GeocodingBatchSize && len(u) <= GeocodingBatchMaxURLLength {
+				t.Errorf("expected location %d to not fit into chunk, got URL of %d bytes", end, len(u))
+			}
+		}
+		start = end
+	}
+	if start != len(locations) {
+		t.Errorf("expected chunks to cover %d locations, got: %d", len(locations), start)
+	}
+}
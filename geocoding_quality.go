@@ -0,0 +1,172 @@
+package mapquest
+
+import (
+	"fmt"
+)
+
+// Granularity is the level of detail of a geocoded location, as
+// encoded in the first two characters of a quality code. Granularities
+// are ordered from the coarsest (GranularityCountry) to the finest
+// (GranularityPoint), so they can be compared with IsAtLeast.
+// See http://open.mapquestapi.com/geocoding/geocodequality.html for details.
+type Granularity int
+
+const (
+	GranularityUnknown      Granularity = iota
+	GranularityCountry                  // A1
+	GranularityState                    // A3
+	GranularityCounty                   // A4
+	GranularityCity                     // A5
+	GranularityZip                      // Z1, Z2, Z3, Z4
+	GranularityNeighborhood             // A6
+	GranularityStreet                   // B1, B2, B3
+	GranularityIntersection             // I1
+	GranularityAddress                  // L1
+	GranularityPoint                    // P1
+)
+
+var granularityCodes = map[string]Granularity{
+	"A1": GranularityCountry,
+	"A3": GranularityState,
+	"A4": GranularityCounty,
+	"A5": GranularityCity,
+	"Z1": GranularityZip,
+	"Z2": GranularityZip,
+	"Z3": GranularityZip,
+	"Z4": GranularityZip,
+	"A6": GranularityNeighborhood,
+	"B1": GranularityStreet,
+	"B2": GranularityStreet,
+	"B3": GranularityStreet,
+	"I1": GranularityIntersection,
+	"L1": GranularityAddress,
+	"P1": GranularityPoint,
+}
+
+// String returns a human-readable name of the granularity.
+func (g Granularity) String() string {
+	switch g {
+	case GranularityCountry:
+		return "country"
+	case GranularityState:
+		return "state"
+	case GranularityCounty:
+		return "county"
+	case GranularityCity:
+		return "city"
+	case GranularityZip:
+		return "zip"
+	case GranularityNeighborhood:
+		return "neighborhood"
+	case GranularityStreet:
+		return "street"
+	case GranularityIntersection:
+		return "intersection"
+	case GranularityAddress:
+		return "address"
+	case GranularityPoint:
+		return "point"
+	}
+	return "unknown"
+}
+
+// Confidence is the confidence level of a part of a geocoded location,
+// as encoded in the last three characters of a quality code.
+type Confidence byte
+
+const (
+	ConfidenceExact         Confidence = 'A'
+	ConfidenceGood          Confidence = 'B'
+	ConfidenceApproximate   Confidence = 'C'
+	ConfidenceNotApplicable Confidence = 'X'
+)
+
+// IsAtLeast returns true if c is at least as confident as min.
+// ConfidenceNotApplicable is considered the lowest confidence.
+func (c Confidence) IsAtLeast(min Confidence) bool {
+	return c.rank() >= min.rank()
+}
+
+func (c Confidence) rank() int {
+	switch c {
+	case ConfidenceExact:
+		return 3
+	case ConfidenceGood:
+		return 2
+	case ConfidenceApproximate:
+		return 1
+	}
+	return 0
+}
+
+// String returns the confidence level as a single character.
+func (c Confidence) String() string {
+	return string(c)
+}
+
+// QualityCode is a parsed geocode quality code like "P1AAA".
+// See http://open.mapquestapi.com/geocoding/geocodequality.html for details.
+type QualityCode struct {
+	// Code is the original quality code, e.g. "P1AAA".
+	Code string
+
+	// Granularity is the level of detail of the location.
+	Granularity Granularity
+
+	// GranularityCode is the original granularity code, e.g. "P1".
+	// Use it to distinguish e.g. B1 and B3, which both have a
+	// granularity of GranularityStreet.
+	GranularityCode string
+
+	// StreetConfidence is the confidence of the street.
+	StreetConfidence Confidence
+
+	// AdminAreaConfidence is the confidence of the administrative
+	// area, e.g. city and state.
+	AdminAreaConfidence Confidence
+
+	// PostalCodeConfidence is the confidence of the postal code.
+	PostalCodeConfidence Confidence
+}
+
+// ParseQualityCode parses a geocode quality code like "P1AAA".
+func ParseQualityCode(code string) (QualityCode, error) {
+	if len(code) != 5 {
+		return QualityCode{}, fmt.Errorf("mapquest: invalid quality code %q", code)
+	}
+	g, found := granularityCodes[code[0:2]]
+	if !found {
+		return QualityCode{}, fmt.Errorf("mapquest: invalid granularity in quality code %q", code)
+	}
+	q := QualityCode{
+		Code:            code,
+		Granularity:     g,
+		GranularityCode: code[0:2],
+	}
+	for i, c := range []*Confidence{&q.StreetConfidence, &q.AdminAreaConfidence, &q.PostalCodeConfidence} {
+		switch conf := Confidence(code[2+i]); conf {
+		case ConfidenceExact, ConfidenceGood, ConfidenceApproximate, ConfidenceNotApplicable:
+			*c = conf
+		default:
+			return QualityCode{}, fmt.Errorf("mapquest: invalid confidence in quality code %q", code)
+		}
+	}
+	return q, nil
+}
+
+// IsAtLeast returns true if the granularity of q is at least g,
+// e.g. q.IsAtLeast(GranularityStreet) is true for street, intersection,
+// address and point granularities.
+func (q QualityCode) IsAtLeast(g Granularity) bool {
+	return q.Granularity >= g
+}
+
+// String returns the original quality code.
+func (q QualityCode) String() string {
+	return q.Code
+}
+
+// QualityCode returns the parsed GeocodeQualityCode of the location.
+func (loc *GeocodingAddressResponseLocation) QualityCode() (QualityCode, error) {
+	return ParseQualityCode(loc.GeocodeQualityCode)
+}
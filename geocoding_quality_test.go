@@ -0,0 +1,90 @@
+package mapquest
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseQualityCode(t *testing.T) {
+	tests := []struct {
+		Code        string
+		Granularity Granularity
+		Street      Confidence
+		AdminArea   Confidence
+		PostalCode  Confidence
+	}{
+		{"P1AAA", GranularityPoint, ConfidenceExact, ConfidenceExact, ConfidenceExact},
+		{"L1BCA", GranularityAddress, ConfidenceGood, ConfidenceApproximate, ConfidenceExact},
+		{"B3AXX", GranularityStreet, ConfidenceExact, ConfidenceNotApplicable, ConfidenceNotApplicable},
+		{"Z1XAA", GranularityZip, ConfidenceNotApplicable, ConfidenceExact, ConfidenceExact},
+		{"A5XAX", GranularityCity, ConfidenceNotApplicable, ConfidenceExact, ConfidenceNotApplicable},
+	}
+	for _, test := range tests {
+		q, err := ParseQualityCode(test.Code)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got: %v", test.Code, err)
+		}
+		if q.Granularity != test.Granularity {
+			t.Errorf("%s: expected granularity %v, got: %v", test.Code, test.Granularity, q.Granularity)
+		}
+		if q.StreetConfidence != test.Street {
+			t.Errorf("%s: expected street confidence %v, got: %v", test.Code, test.Street, q.StreetConfidence)
+		}
+		if q.AdminAreaConfidence != test.AdminArea {
+			t.Errorf("%s: expected admin area confidence %v, got: %v", test.Code, test.AdminArea, q.AdminAreaConfidence)
+		}
+		if q.PostalCodeConfidence != test.PostalCode {
+			t.Errorf("%s: expected postal code confidence %v, got: %v", test.Code, test.PostalCode, q.PostalCodeConfidence)
+		}
+		if q.String() != test.Code {
+			t.Errorf("%s: expected String() to return %q, got: %q", test.Code, test.Code, q.String())
+		}
+	}
+
+	for _, code := range []string{"", "P1AA", "Q1AAA", "P1AAD"} {
+		if _, err := ParseQualityCode(code); err == nil {
+			t.Errorf("%q: expected error, got: nil", code)
+		}
+	}
+}
+
+func TestQualityCodeIsAtLeast(t *testing.T) {
+	q, err := ParseQualityCode("I1AAA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.IsAtLeast(GranularityStreet) {
+		t.Error("expected intersection to be at least street")
+	}
+	if !q.IsAtLeast(GranularityIntersection) {
+		t.Error("expected intersection to be at least intersection")
+	}
+	if q.IsAtLeast(GranularityAddress) {
+		t.Error("expected intersection not to be at least address")
+	}
+
+	if !ConfidenceGood.IsAtLeast(ConfidenceApproximate) {
+		t.Error("expected B to be at least C")
+	}
+	if ConfidenceNotApplicable.IsAtLeast(ConfidenceApproximate) {
+		t.Error("expected X not to be at least C")
+	}
+}
+
+func TestGeocodingAddressResponseLocationQualityCode(t *testing.T) {
+	var loc GeocodingAddressResponseLocation
+	data := `{"geocodeQuality":"POINT","geocodeQualityCode":"P1AAA"}`
+	if err := json.Unmarshal([]byte(data), &loc); err != nil {
+		t.Fatal(err)
+	}
+	if loc.GeocodeQuality != "POINT" {
+		t.Errorf("expected GeocodeQuality %q, got: %q", "POINT", loc.GeocodeQuality)
+	}
+	q, err := loc.QualityCode()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if q.Granularity != GranularityPoint {
+		t.Errorf("expected granularity %v, got: %v", GranularityPoint, q.Granularity)
+	}
+}
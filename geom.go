@@ -1,11 +1,144 @@
 package mapquest
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 type GeoPoint struct {
 	Latitude  float64
 	Longitude float64
 }
 
+// valid returns true if p is in the range of valid coordinates.
+func (p GeoPoint) valid() bool {
+	return p.Latitude >= -90 && p.Latitude <= 90 &&
+		p.Longitude >= -180 && p.Longitude <= 180
+}
+
+// latLng is the JSON representation of a GeoPoint in requests
+// to and responses from MapQuest.
+type latLng struct {
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"lng"`
+}
+
+func newLatLng(p GeoPoint) latLng {
+	return latLng{Latitude: p.Latitude, Longitude: p.Longitude}
+}
+
+func (ll latLng) point() GeoPoint {
+	return GeoPoint{Latitude: ll.Latitude, Longitude: ll.Longitude}
+}
+
 type GeoBox struct {
 	A GeoPoint
 	B GeoPoint
 }
+
+// GeoLine is a line, e.g. the shape of a route, given by its points.
+type GeoLine []GeoPoint
+
+// GeoLineFromFlat creates a GeoLine from a flat list of alternating
+// latitudes and longitudes, as returned e.g. by the Directions API.
+func GeoLineFromFlat(coords []float64) (GeoLine, error) {
+	if len(coords)%2 != 0 {
+		return nil, fmt.Errorf("mapquest: uneven number of coordinates: %d", len(coords))
+	}
+	line := make(GeoLine, len(coords)/2)
+	for i := range line {
+		line[i] = GeoPoint{
+			Latitude:  coords[2*i],
+			Longitude: coords[2*i+1],
+		}
+	}
+	return line, nil
+}
+
+// Flat returns the points of the line as a flat list of
+// alternating latitudes and longitudes.
+func (l GeoLine) Flat() []float64 {
+	coords := make([]float64, 0, 2*len(l))
+	for _, pt := range l {
+		coords = append(coords, pt.Latitude, pt.Longitude)
+	}
+	return coords
+}
+
+// GeoJSONGeometry is a geometry in GeoJSON format, e.g. the outline
+// of a place returned by the Nominatim API.
+// See http://geojson.org/geojson-spec.html#geometry-objects for details.
+type GeoJSONGeometry struct {
+	// Type of the geometry, e.g. "Point", "LineString", "Polygon",
+	// or "MultiPolygon".
+	Type string `json:"type"`
+
+	// Coordinates of the geometry. Their structure depends on Type.
+	// Use e.g. Polygons to decode them.
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// Point returns the position of a geometry of type "Point".
+func (g *GeoJSONGeometry) Point() (GeoPoint, error) {
+	if g.Type != "Point" {
+		return GeoPoint{}, fmt.Errorf("mapquest: geometry of type %q is not a point", g.Type)
+	}
+	var pos []float64
+	if err := json.Unmarshal(g.Coordinates, &pos); err != nil {
+		return GeoPoint{}, err
+	}
+	if len(pos) < 2 {
+		return GeoPoint{}, fmt.Errorf("mapquest: invalid GeoJSON position %v", pos)
+	}
+	return GeoPoint{Latitude: pos[1], Longitude: pos[0]}, nil
+}
+
+// Polygons returns the polygons of a geometry of type "Polygon" or
+// "MultiPolygon". Each polygon consists of one or more rings: The first
+// ring is the outline, the others are holes. A "Polygon" geometry
+// returns a single polygon.
+func (g *GeoJSONGeometry) Polygons() ([][][]GeoPoint, error) {
+	switch g.Type {
+	case "Polygon":
+		var coords [][][]float64
+		if err := json.Unmarshal(g.Coordinates, &coords); err != nil {
+			return nil, err
+		}
+		polygon, err := geoJSONRings(coords)
+		if err != nil {
+			return nil, err
+		}
+		return [][][]GeoPoint{polygon}, nil
+	case "MultiPolygon":
+		var coords [][][][]float64
+		if err := json.Unmarshal(g.Coordinates, &coords); err != nil {
+			return nil, err
+		}
+		polygons := make([][][]GeoPoint, len(coords))
+		for i, c := range coords {
+			polygon, err := geoJSONRings(c)
+			if err != nil {
+				return nil, err
+			}
+			polygons[i] = polygon
+		}
+		return polygons, nil
+	}
+	return nil, fmt.Errorf("mapquest: geometry of type %q is not a polygon", g.Type)
+}
+
+// geoJSONRings converts GeoJSON rings into GeoPoints.
+// Notice that GeoJSON positions are in longitude, latitude order.
+func geoJSONRings(coords [][][]float64) ([][]GeoPoint, error) {
+	rings := make([][]GeoPoint, len(coords))
+	for i, ring := range coords {
+		rings[i] = make([]GeoPoint, len(ring))
+		for j, pos := range ring {
+			if len(pos) < 2 {
+				return nil, fmt.Errorf("mapquest: invalid GeoJSON position %v", pos)
+			}
+			rings[i][j] = GeoPoint{Latitude: pos[1], Longitude: pos[0]}
+		}
+	}
+	return rings, nil
+}
@@ -1,9 +1,16 @@
 package mapquest
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"math"
+	"net/http"
 	"net/url"
+	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -12,6 +19,10 @@ var _ = log.Print
 const (
 	// NominatimPathPrefix is the default path prefix for the Nominatim API.
 	NominatimPathPrefix = "/nominatim/v1"
+
+	// NominatimLookupLimit is the maximum number of OSM ids that
+	// Nominatim accepts in a single lookup request.
+	NominatimLookupLimit = 50
 )
 
 // NominatimAPI is a geographic search service that relies solely on the
@@ -23,6 +34,12 @@ type NominatimAPI struct {
 
 // Search searches for details given an address.
 func (api *NominatimAPI) Search(req *NominatimSearchRequest) (*NominatimSearchResponse, error) {
+	return api.SearchContext(context.Background(), req)
+}
+
+// SearchContext is like Search, but binds the request to ctx.
+// Cancelling ctx aborts the request to MapQuest.
+func (api *NominatimAPI) SearchContext(ctx context.Context, req *NominatimSearchRequest) (*NominatimSearchResponse, error) {
 	u, err := api.buildSearchURL(req)
 	if err != nil {
 		return nil, err
@@ -31,7 +48,107 @@ func (api *NominatimAPI) Search(req *NominatimSearchRequest) (*NominatimSearchRe
 	res := new(NominatimSearchResponse)
 	res.Results = make([]*NominatimSearchResult, 0)
 
-	if err := api.c.getJSON(u, &res.Results); err != nil {
+	if err := api.c.getJSON(ctx, ServiceNominatim, u, &res.Results); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Reverse returns the place at a specific location or
+// with a specific OSM id.
+func (api *NominatimAPI) Reverse(req *NominatimReverseRequest) (*NominatimSearchResult, error) {
+	return api.ReverseContext(context.Background(), req)
+}
+
+// ReverseContext is like Reverse, but binds the request to ctx.
+// Cancelling ctx aborts the request to MapQuest.
+func (api *NominatimAPI) ReverseContext(ctx context.Context, req *NominatimReverseRequest) (*NominatimSearchResult, error) {
+	u, err := api.buildReverseURL(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var data json.RawMessage
+	if err := api.c.getJSON(ctx, ServiceNominatim, u, &data); err != nil {
+		return nil, err
+	}
+
+	// Nominatim reports errors in the body, e.g. if nothing was found
+	var e struct {
+		Error string `json:"error,omitempty"`
+	}
+	if err := json.Unmarshal(data, &e); err == nil && e.Error != "" {
+		return nil, &APIError{
+			StatusCode: http.StatusOK,
+			Messages:   []string{e.Error},
+			URL:        redactURL(u),
+		}
+	}
+
+	res := new(NominatimSearchResult)
+	if err := json.Unmarshal(data, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Lookup returns the places with specific OSM ids. If there are more
+// than NominatimLookupLimit ids, they are looked up in chunks, one
+// after another, and the results are merged.
+func (api *NominatimAPI) Lookup(req *NominatimLookupRequest) (*NominatimSearchResponse, error) {
+	return api.LookupContext(context.Background(), req)
+}
+
+// LookupContext is like Lookup, but binds the requests to ctx.
+// Cancelling ctx aborts the requests to MapQuest.
+func (api *NominatimAPI) LookupContext(ctx context.Context, req *NominatimLookupRequest) (*NominatimSearchResponse, error) {
+	if err := validateOSMIds(req.OSMIds); err != nil {
+		return nil, err
+	}
+
+	res := new(NominatimSearchResponse)
+	res.Results = make([]*NominatimSearchResult, 0, len(req.OSMIds))
+
+	for start := 0; start < len(req.OSMIds); start += NominatimLookupLimit {
+		end := start + NominatimLookupLimit
+		if end > len(req.OSMIds) {
+			end = len(req.OSMIds)
+		}
+		chunk := *req
+		chunk.OSMIds = req.OSMIds[start:end]
+
+		u, err := api.buildLookupURL(&chunk)
+		if err != nil {
+			return nil, err
+		}
+		var results []*NominatimSearchResult
+		if err := api.c.getJSON(ctx, ServiceNominatim, u, &results); err != nil {
+			return nil, err
+		}
+		res.Results = append(res.Results, results...)
+	}
+
+	return res, nil
+}
+
+// Details returns details about a specific place, e.g. its
+// address hierarchy and linked places.
+func (api *NominatimAPI) Details(req *NominatimDetailsRequest) (*NominatimDetailsResult, error) {
+	return api.DetailsContext(context.Background(), req)
+}
+
+// DetailsContext is like Details, but binds the request to ctx.
+// Cancelling ctx aborts the request to MapQuest.
+func (api *NominatimAPI) DetailsContext(ctx context.Context, req *NominatimDetailsRequest) (*NominatimDetailsResult, error) {
+	u, err := api.buildDetailsURL(req)
+	if err != nil {
+		return nil, err
+	}
+
+	res := new(NominatimDetailsResult)
+	if err := api.c.getJSON(ctx, ServiceNominatim, u, res); err != nil {
 		return nil, err
 	}
 
@@ -79,21 +196,61 @@ func (api *NominatimAPI) buildSearchURL(req *NominatimSearchRequest) (string, er
 	if len(req.CountryCodes) > 0 {
 		q.Set("countrycodes", strings.Join(req.CountryCodes, ","))
 	}
-	if len(req.ViewBox) == 4 {
-		q.Set("viewbox", fmt.Sprintf("%f,%f,%f,%f", req.ViewBox[0], req.ViewBox[1], req.ViewBox[2], req.ViewBox[3]))
+	if box := req.ViewBox; box != nil {
+		if !box.A.valid() || !box.B.valid() {
+			return "", fmt.Errorf("mapquest: invalid viewbox %v", *box)
+		}
+		// Nominatim expects left, top, right, bottom
+		q.Set("viewbox", fmt.Sprintf("%f,%f,%f,%f",
+			math.Min(box.A.Longitude, box.B.Longitude),
+			math.Max(box.A.Latitude, box.B.Latitude),
+			math.Max(box.A.Longitude, box.B.Longitude),
+			math.Min(box.A.Latitude, box.B.Latitude)))
 	}
+	setNominatimLanguage(q, api.c.locale, req.Language)
 	if len(req.ExcludePlaceIds) > 0 {
 		q.Set("exclude_place_ids", strings.Join(req.ExcludePlaceIds, ","))
 	}
 	if req.Bounded != nil {
 		if *req.Bounded {
+			if req.ViewBox == nil && len(req.Route) == 0 {
+				return "", fmt.Errorf("mapquest: bounded search requires a viewbox or a route")
+			}
 			q.Set("bounded", "1")
 		} else {
 			q.Set("bounded", "0")
 		}
 	}
-	// TODO(oe): routewidth
+	if len(req.Route) > 0 {
+		if req.ViewBox != nil {
+			return "", fmt.Errorf("mapquest: specify either a viewbox or a route, not both")
+		}
+		if len(req.Route) < 2 {
+			return "", fmt.Errorf("mapquest: route requires at least 2 points, got %d", len(req.Route))
+		}
+		if req.RouteWidth == nil {
+			// Nominatim ignores the route without a route width
+			return "", fmt.Errorf("mapquest: route requires a route width")
+		}
+		// Nominatim expects lon,lat pairs
+		parts := make([]string, 0, 2*len(req.Route))
+		for _, pt := range req.Route {
+			if !pt.valid() {
+				return "", fmt.Errorf("mapquest: invalid point %v in route", pt)
+			}
+			parts = append(parts,
+				strconv.FormatFloat(pt.Longitude, 'f', 6, 64),
+				strconv.FormatFloat(pt.Latitude, 'f', 6, 64))
+		}
+		q.Set("route", strings.Join(parts, ","))
+	}
 	if req.RouteWidth != nil {
+		if len(req.Route) == 0 {
+			return "", fmt.Errorf("mapquest: route width requires a route")
+		}
+		if *req.RouteWidth <= 0 {
+			return "", fmt.Errorf("mapquest: route width must be positive, got %f", *req.RouteWidth)
+		}
 		q.Set("routewidth", fmt.Sprintf("%f", *req.RouteWidth))
 	}
 	if req.OSMType != "" {
@@ -102,28 +259,297 @@ func (api *NominatimAPI) buildSearchURL(req *NominatimSearchRequest) (string, er
 	if req.OSMId != "" {
 		q.Set("osm_id", req.OSMId)
 	}
+	setNominatimDetails(q, req.PolygonGeoJSON, req.ExtraTags, req.NameDetails)
+
+	// No key here!
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// buildReverseURL returns the complete URL for the request,
+// including the key to query the MapQuest API.
+func (api *NominatimAPI) buildReverseURL(req *NominatimReverseRequest) (string, error) {
+	urls := fmt.Sprintf("%s%s/reverse.php", api.c.BaseURL(), NominatimPathPrefix)
+	u, err := url.Parse(urls)
+	if err != nil {
+		return "", err
+	}
+
+	// Add key and other parameters to the query string
+	q := u.Query()
+	q.Set("format", "json")
+	switch {
+	case req.OSMType != "" && req.OSMId != "":
+		q.Set("osm_type", req.OSMType)
+		q.Set("osm_id", req.OSMId)
+	case req.Location != nil:
+		q.Set("lat", fmt.Sprintf("%f", req.Location.Latitude))
+		q.Set("lon", fmt.Sprintf("%f", req.Location.Longitude))
+	default:
+		return "", fmt.Errorf("mapquest: reverse geocoding requires a location or an OSM type and id")
+	}
+	if req.Zoom > 0 {
+		q.Set("zoom", fmt.Sprintf("%d", req.Zoom))
+	}
+	if req.AddressDetails == nil || *req.AddressDetails {
+		q.Set("addressdetails", "1")
+	} else {
+		q.Set("addressdetails", "0")
+	}
+	setNominatimDetails(q, req.PolygonGeoJSON, req.ExtraTags, req.NameDetails)
+	setNominatimLanguage(q, api.c.locale, "")
+
+	// No key here!
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// validateOSMIds checks that ids is not empty and each id consists
+// of its type (N, W, or R) and a numeric id.
+func validateOSMIds(ids []string) error {
+	if len(ids) == 0 {
+		return fmt.Errorf("mapquest: lookup requires at least one OSM id")
+	}
+	for _, id := range ids {
+		if len(id) < 2 || !strings.ContainsAny(id[0:1], "NWR") {
+			return fmt.Errorf("mapquest: invalid OSM id %q; expected e.g. N123, W456, or R789", id)
+		}
+		if _, err := strconv.ParseUint(id[1:], 10, 64); err != nil {
+			return fmt.Errorf("mapquest: invalid OSM id %q; expected e.g. N123, W456, or R789", id)
+		}
+	}
+	return nil
+}
+
+// buildLookupURL returns the complete URL for the request,
+// including the key to query the MapQuest API.
+func (api *NominatimAPI) buildLookupURL(req *NominatimLookupRequest) (string, error) {
+	if err := validateOSMIds(req.OSMIds); err != nil {
+		return "", err
+	}
+	if n := len(req.OSMIds); n > NominatimLookupLimit {
+		return "", fmt.Errorf("mapquest: lookup accepts at most %d OSM ids, got %d", NominatimLookupLimit, n)
+	}
+
+	urls := fmt.Sprintf("%s%s/lookup.php", api.c.BaseURL(), NominatimPathPrefix)
+	u, err := url.Parse(urls)
+	if err != nil {
+		return "", err
+	}
+
+	// Add key and other parameters to the query string
+	q := u.Query()
+	q.Set("format", "json")
+	q.Set("osm_ids", strings.Join(req.OSMIds, ","))
+	if req.AddressDetails == nil || *req.AddressDetails {
+		q.Set("addressdetails", "1")
+	} else {
+		q.Set("addressdetails", "0")
+	}
+	setNominatimDetails(q, req.PolygonGeoJSON, req.ExtraTags, req.NameDetails)
+	setNominatimLanguage(q, api.c.locale, "")
 
 	// No key here!
 	u.RawQuery = q.Encode()
 	return u.String(), nil
 }
 
+// buildDetailsURL returns the complete URL for the request,
+// including the key to query the MapQuest API.
+func (api *NominatimAPI) buildDetailsURL(req *NominatimDetailsRequest) (string, error) {
+	urls := fmt.Sprintf("%s%s/details.php", api.c.BaseURL(), NominatimPathPrefix)
+	u, err := url.Parse(urls)
+	if err != nil {
+		return "", err
+	}
+
+	// Add key and other parameters to the query string
+	q := u.Query()
+	q.Set("format", "json")
+	switch {
+	case req.PlaceId != "":
+		q.Set("place_id", req.PlaceId)
+	case req.OSMType != "" && req.OSMId != "":
+		q.Set("osmtype", req.OSMType)
+		q.Set("osmid", req.OSMId)
+	default:
+		return "", fmt.Errorf("mapquest: details require a place id or an OSM type and id")
+	}
+	if req.AddressDetails {
+		q.Set("addressdetails", "1")
+	}
+	if req.Hierarchy {
+		q.Set("hierarchy", "1")
+	}
+	if req.LinkedPlaces {
+		q.Set("linkedplaces", "1")
+	}
+	if req.PolygonGeoJSON {
+		q.Set("polygon_geojson", "1")
+	}
+	setNominatimLanguage(q, api.c.locale, "")
+
+	// No key here!
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// setNominatimDetails adds the parameters for optional details
+// of the results to q.
+func setNominatimDetails(q url.Values, polygonGeoJSON, extraTags, nameDetails bool) {
+	if polygonGeoJSON {
+		q.Set("polygon_geojson", "1")
+	}
+	if extraTags {
+		q.Set("extratags", "1")
+	}
+	if nameDetails {
+		q.Set("namedetails", "1")
+	}
+}
+
+// setNominatimLanguage adds the preferred language of the results
+// to q, using the language of the request or the default of the client.
+func setNominatimLanguage(q url.Values, defaultLocale, locale string) {
+	if locale == "" {
+		locale = defaultLocale
+	}
+	if locale != "" {
+		q.Set("accept-language", acceptLanguage(locale))
+	}
+}
+
+// NominatimReverseRequest is a request to find the place at a
+// specific location, or with a specific OSM id.
+type NominatimReverseRequest struct {
+	// Location to find the place for.
+	Location *GeoPoint
+
+	// Zoom specifies the level of detail of the place, in the range
+	// of 0 (country) to 18 (house/building).
+	Zoom int
+
+	// OSMType is the type of the OSM object to look up instead of
+	// Location, i.e. "N" (node), "W" (way) or "R" (relation).
+	// It must be specified together with OSMId.
+	OSMType string
+
+	// OSMId is the id of the OSM object to look up instead of Location.
+	OSMId string
+
+	// AddressDetails specifies whether to return the address broken
+	// down into its components. The default is true.
+	AddressDetails *bool
+
+	// PolygonGeoJSON returns the outline of the place as GeoJSON.
+	PolygonGeoJSON bool
+
+	// ExtraTags returns additional OSM tags of the place,
+	// e.g. opening hours or the Wikidata id.
+	ExtraTags bool
+
+	// NameDetails returns all names of the place,
+	// e.g. in different languages.
+	NameDetails bool
+}
+
+// NominatimLookupRequest is a request to find places by their OSM ids.
+type NominatimLookupRequest struct {
+	// OSMIds of the places to look up. Each id is prefixed by its
+	// type, e.g. "N123" (node), "W456" (way), or "R789" (relation).
+	// Lookup splits more than NominatimLookupLimit ids into
+	// several requests.
+	OSMIds []string
+
+	// AddressDetails specifies whether to return the address broken
+	// down into its components. The default is true.
+	AddressDetails *bool
+
+	// PolygonGeoJSON returns the outline of each place as GeoJSON.
+	PolygonGeoJSON bool
+
+	// ExtraTags returns additional OSM tags of each place,
+	// e.g. opening hours or the Wikidata id.
+	ExtraTags bool
+
+	// NameDetails returns all names of each place,
+	// e.g. in different languages.
+	NameDetails bool
+}
+
+// NominatimDetailsRequest is a request for details about a place,
+// specified either by its place id or by its OSM type and id.
+type NominatimDetailsRequest struct {
+	// PlaceId of the place, as returned e.g. by Search.
+	PlaceId string
+
+	// OSMType of the place, i.e. "N" (node), "W" (way), or "R" (relation).
+	// It must be specified together with OSMId.
+	OSMType string
+
+	// OSMId of the place.
+	OSMId string
+
+	// AddressDetails returns the address lines of the place.
+	AddressDetails bool
+
+	// Hierarchy returns the places that are part of the place,
+	// grouped by their type.
+	Hierarchy bool
+
+	// LinkedPlaces returns the places linked to the place.
+	LinkedPlaces bool
+
+	// PolygonGeoJSON returns the outline of the place as GeoJSON.
+	PolygonGeoJSON bool
+}
+
 type NominatimSearchRequest struct {
-	Query           string
-	Street          string
-	City            string
-	County          string
-	State           string
-	Country         string
-	PostalCode      string
-	Limit           int
-	CountryCodes    []string
-	ViewBox         []float64
+	Query        string
+	Street       string
+	City         string
+	County       string
+	State        string
+	Country      string
+	PostalCode   string
+	Limit        int
+	CountryCodes []string
+
+	// Language is the preferred language of the results, e.g. "de"
+	// or "fr-FR". It overrides the locale of the client.
+	Language string
+
+	// ViewBox is the preferred area to find results in. Results
+	// outside of the area are ranked lower, unless Bounded is true,
+	// in which case they are excluded.
+	ViewBox *GeoBox
+
 	ExcludePlaceIds []string
 	Bounded         *bool
-	RouteWidth      *float64
-	OSMType         string
-	OSMId           string
+
+	// Route searches for results along the given line, e.g. to find
+	// POIs along a delivery path. It must have at least 2 points,
+	// requires RouteWidth, and cannot be combined with ViewBox. Set Bounded to true to exclude
+	// results that are not along the route.
+	Route []GeoPoint
+
+	// RouteWidth is the width of the area around Route to search in,
+	// in degrees. Route and RouteWidth must be specified together.
+	RouteWidth *float64
+
+	OSMType string
+	OSMId   string
+
+	// PolygonGeoJSON returns the outline of each place as GeoJSON.
+	PolygonGeoJSON bool
+
+	// ExtraTags returns additional OSM tags of each place,
+	// e.g. opening hours or the Wikidata id.
+	ExtraTags bool
+
+	// NameDetails returns all names of each place,
+	// e.g. in different languages.
+	NameDetails bool
 }
 
 type NominatimSearchResponse struct {
@@ -131,32 +557,225 @@ type NominatimSearchResponse struct {
 }
 
 type NominatimSearchResult struct {
-	Address *struct {
-		City          string `json:"city,omitempty"`
-		CityDistrict  string `json:"city_district,omitempty"`
-		Continent     string `json:"continent,omitempty"`
-		Country       string `json:"country,omitempty"`
-		CountryCode   string `json:"country_code,omitempty"`
-		County        string `json:"county,omitempty"`
-		Hamlet        string `json:"hamlet,omitempty"`
-		HouseNumber   string `json:"house_number,omitempty"`
-		Pedestrian    string `json:"pedestrian,omitempty"`
-		Neighbourhood string `json:"neighbourhood,omitempty"`
-		PostCode      string `json:"postcode,omitempty"`
-		Road          string `json:"road,omitempty"`
-		State         string `json:"state,omitempty"`
-		StateDistrict string `json:"state_district,omitempty"`
-		Suburb        string `json:"suburb,omitempty"`
-	} `json:"address,omitempty"`
-	//BoundingBox []float64 `json:"boundingbox,omitempty"`
-	Class       string  `json:"class,omitempty"`
-	DisplayName string  `json:"display_name,omitempty"`
-	Importance  float64 `json:"importance,omitempty"`
-	Latitude    float64 `json:"lat,string,omitempty"`
-	Longitude   float64 `json:"lon,string,omitempty"`
-	OSMId       string  `json:"osm_id,omitempty"`
-	OSMType     string  `json:"osm_type,omitempty"`
-	PlaceId     string  `json:"place_id,omitempty"`
-	Type        string  `json:"type,omitempty"`
-	License     string  `json:"licence,omitempty"` // typo in API?
+	Address     *NominatimAddress `json:"address,omitempty"`
+	BoundingBox *GeoBox           `json:"-"`
+	Class       string            `json:"class,omitempty"`
+	DisplayName string            `json:"display_name,omitempty"`
+	ExtraTags   NominatimTags     `json:"extratags,omitempty"`
+	Geometry    *GeoJSONGeometry  `json:"geojson,omitempty"`
+	Importance  float64           `json:"importance,omitempty"`
+	Latitude    float64           `json:"lat,string,omitempty"`
+	Longitude   float64           `json:"lon,string,omitempty"`
+	NameDetails NominatimTags     `json:"namedetails,omitempty"`
+	OSMId       json.Number       `json:"osm_id,omitempty"`
+	OSMType     string            `json:"osm_type,omitempty"`
+	PlaceId     json.Number       `json:"place_id,omitempty"`
+	Type        string            `json:"type,omitempty"`
+	License     string            `json:"licence,omitempty"` // typo in API?
+}
+
+// NominatimDetailsResult is the result of a details request.
+type NominatimDetailsResult struct {
+	Address            []*NominatimDetailsPlace            `json:"address,omitempty"`
+	AddressTags        NominatimTags                       `json:"addresstags,omitempty"`
+	AdminLevel         int                                 `json:"admin_level,omitempty"`
+	CalculatedPostcode string                              `json:"calculated_postcode,omitempty"`
+	Category           string                              `json:"category,omitempty"`
+	Centroid           *GeoJSONGeometry                    `json:"centroid,omitempty"`
+	CountryCode        string                              `json:"country_code,omitempty"`
+	ExtraTags          NominatimTags                       `json:"extratags,omitempty"`
+	Geometry           *GeoJSONGeometry                    `json:"geometry,omitempty"`
+	Hierarchy          map[string][]*NominatimDetailsPlace `json:"hierarchy,omitempty"`
+	HouseNumber        string                              `json:"housenumber,omitempty"`
+	Importance         float64                             `json:"importance,omitempty"`
+	IndexedDate        string                              `json:"indexed_date,omitempty"`
+	IsArea             bool                                `json:"isarea,omitempty"`
+	LinkedPlaces       []*NominatimDetailsPlace            `json:"linked_places,omitempty"`
+	LocalName          string                              `json:"localname,omitempty"`
+	Names              NominatimTags                       `json:"names,omitempty"`
+	OSMId              json.Number                         `json:"osm_id,omitempty"`
+	OSMType            string                              `json:"osm_type,omitempty"`
+	ParentPlaceId      json.Number                         `json:"parent_place_id,omitempty"`
+	PlaceId            json.Number                         `json:"place_id,omitempty"`
+	RankAddress        int                                 `json:"rank_address,omitempty"`
+	RankSearch         int                                 `json:"rank_search,omitempty"`
+	Type               string                              `json:"type,omitempty"`
+}
+
+// NominatimDetailsPlace is a place related to the place of a
+// details request, e.g. one of its address lines or a linked place.
+type NominatimDetailsPlace struct {
+	AdminLevel  int         `json:"admin_level,omitempty"`
+	Class       string      `json:"class,omitempty"`
+	Distance    float64     `json:"distance,omitempty"`
+	IsAddress   bool        `json:"isaddress,omitempty"`
+	LocalName   string      `json:"localname,omitempty"`
+	OSMId       json.Number `json:"osm_id,omitempty"`
+	OSMType     string      `json:"osm_type,omitempty"`
+	PlaceId     json.Number `json:"place_id,omitempty"`
+	PlaceType   string      `json:"place_type,omitempty"`
+	RankAddress int         `json:"rank_address,omitempty"`
+	Type        string      `json:"type,omitempty"`
+}
+
+// NominatimTags are OSM tags of a place, e.g. its names or
+// extra tags like opening hours.
+type NominatimTags map[string]string
+
+// UnmarshalJSON decodes tags. Nominatim returns an empty array
+// instead of an empty object if a place has no tags.
+func (t *NominatimTags) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("[]")) {
+		*t = nil
+		return nil
+	}
+	var m map[string]string
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	*t = m
+	return nil
+}
+
+// NominatimAddress is the address of a place, broken down into its
+// components. Nominatim returns different components depending on the
+// place and the country. The most common ones are available as fields,
+// all others are kept in Other.
+type NominatimAddress struct {
+	Building      string `json:"building,omitempty"`
+	City          string `json:"city,omitempty"`
+	CityDistrict  string `json:"city_district,omitempty"`
+	Continent     string `json:"continent,omitempty"`
+	Country       string `json:"country,omitempty"`
+	CountryCode   string `json:"country_code,omitempty"`
+	County        string `json:"county,omitempty"`
+	Cycleway      string `json:"cycleway,omitempty"`
+	Footway       string `json:"footway,omitempty"`
+	Hamlet        string `json:"hamlet,omitempty"`
+	HouseNumber   string `json:"house_number,omitempty"`
+	ISO3166Lvl4   string `json:"ISO3166-2-lvl4,omitempty"`
+	Municipality  string `json:"municipality,omitempty"`
+	Neighbourhood string `json:"neighbourhood,omitempty"`
+	Path          string `json:"path,omitempty"`
+	Pedestrian    string `json:"pedestrian,omitempty"`
+	PostCode      string `json:"postcode,omitempty"`
+	Region        string `json:"region,omitempty"`
+	Road          string `json:"road,omitempty"`
+	State         string `json:"state,omitempty"`
+	StateDistrict string `json:"state_district,omitempty"`
+	Suburb        string `json:"suburb,omitempty"`
+	Town          string `json:"town,omitempty"`
+	Village       string `json:"village,omitempty"`
+
+	// Other contains all components that are not available as fields,
+	// e.g. "amenity" or "ISO3166-2-lvl6".
+	Other map[string]string `json:"-"`
+}
+
+// nominatimAddressKeys is the set of keys available as fields
+// in NominatimAddress.
+var nominatimAddressKeys = func() map[string]bool {
+	keys := make(map[string]bool)
+	t := reflect.TypeOf(NominatimAddress{})
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			keys[name] = true
+		}
+	}
+	return keys
+}()
+
+// Locality returns the name of the city, town, village, or whatever
+// best describes the locality of the address.
+func (a *NominatimAddress) Locality() string {
+	return firstNonEmpty(a.City, a.Town, a.Village, a.Municipality, a.Hamlet)
+}
+
+// Street returns the name of the road, pedestrian zone, or whatever
+// best describes the street of the address.
+func (a *NominatimAddress) Street() string {
+	return firstNonEmpty(a.Road, a.Pedestrian, a.Footway, a.Cycleway, a.Path)
+}
+
+// UnmarshalJSON decodes an address returned by the Nominatim API,
+// keeping all unknown components in Other.
+func (a *NominatimAddress) UnmarshalJSON(data []byte) error {
+	type address NominatimAddress // prevent recursion
+	if err := json.Unmarshal(data, (*address)(a)); err != nil {
+		return err
+	}
+	var all map[string]string
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	a.Other = nil
+	for k, v := range all {
+		if nominatimAddressKeys[k] {
+			continue
+		}
+		if a.Other == nil {
+			a.Other = make(map[string]string)
+		}
+		a.Other[k] = v
+	}
+	return nil
+}
+
+// MarshalJSON encodes the address, including all components in Other.
+func (a *NominatimAddress) MarshalJSON() ([]byte, error) {
+	type address NominatimAddress // prevent recursion
+	data, err := json.Marshal((*address)(a))
+	if err != nil || len(a.Other) == 0 {
+		return data, err
+	}
+	all := make(map[string]string)
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	for k, v := range a.Other {
+		if _, found := all[k]; !found {
+			all[k] = v
+		}
+	}
+	return json.Marshal(all)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// UnmarshalJSON decodes a result returned by the Nominatim API.
+func (r *NominatimSearchResult) UnmarshalJSON(data []byte) error {
+	type result NominatimSearchResult // prevent recursion
+	v := struct {
+		*result
+		BoundingBox []string `json:"boundingbox,omitempty"`
+	}{
+		result: (*result)(r),
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	r.BoundingBox = nil
+	if len(v.BoundingBox) == 4 {
+		// Nominatim returns min lat, max lat, min lon, max lon
+		var f [4]float64
+		for i, s := range v.BoundingBox {
+			var err error
+			if f[i], err = strconv.ParseFloat(s, 64); err != nil {
+				return fmt.Errorf("mapquest: invalid bounding box %v: %v", v.BoundingBox, err)
+			}
+		}
+		r.BoundingBox = &GeoBox{
+			A: GeoPoint{Latitude: f[0], Longitude: f[2]},
+			B: GeoPoint{Latitude: f[1], Longitude: f[3]},
+		}
+	}
+	return nil
 }
@@ -2,7 +2,14 @@ package mapquest
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 )
 
@@ -13,6 +20,9 @@ func TestNominatimBuildSearchURLs(t *testing.T) {
 		return
 	}
 
+	bounded := true
+	routeWidth := 0.01
+
 	tests := []struct {
 		Request *NominatimSearchRequest
 		URL     string
@@ -24,6 +34,37 @@ func TestNominatimBuildSearchURLs(t *testing.T) {
 			},
 			URL: "http://open.mapquestapi.com/nominatim/v1/search.php?addressdetails=1&format=json&limit=1&q=Marienplatz+2a%2C+M%C3%BCnchen%2C+DE",
 		},
+		{
+			Request: &NominatimSearchRequest{
+				Query:          "Berlin",
+				PolygonGeoJSON: true,
+				ExtraTags:      true,
+				NameDetails:    true,
+			},
+			URL: "http://open.mapquestapi.com/nominatim/v1/search.php?addressdetails=1&extratags=1&format=json&namedetails=1&polygon_geojson=1&q=Berlin",
+		},
+		{
+			Request: &NominatimSearchRequest{
+				Query: "pharmacy",
+				ViewBox: &GeoBox{
+					A: GeoPoint{Latitude: 52.4, Longitude: 13.5},
+					B: GeoPoint{Latitude: 52.6, Longitude: 13.3},
+				},
+				Bounded: &bounded,
+			},
+			URL: "http://open.mapquestapi.com/nominatim/v1/search.php?addressdetails=1&bounded=1&format=json&q=pharmacy&viewbox=13.300000%2C52.600000%2C13.500000%2C52.400000",
+		},
+		{
+			Request: &NominatimSearchRequest{
+				Query: "fuel",
+				Route: []GeoPoint{
+					{Latitude: 52.5, Longitude: 13.3},
+					{Latitude: 52.6, Longitude: 13.4},
+				},
+				RouteWidth: &routeWidth,
+			},
+			URL: "http://open.mapquestapi.com/nominatim/v1/search.php?addressdetails=1&format=json&q=fuel&route=13.300000%2C52.500000%2C13.400000%2C52.600000&routewidth=0.010000",
+		},
 	}
 
 	client := NewClient(testKey)
@@ -38,6 +79,412 @@ func TestNominatimBuildSearchURLs(t *testing.T) {
 	}
 }
 
+func TestNominatimLanguage(t *testing.T) {
+	client := NewClient("my-key")
+	client.SetLocale("fr_FR")
+
+	got, err := client.Nominatim().buildSearchURL(&NominatimSearchRequest{Query: "Berlin"})
+	if err != nil {
+		t.Fatalf("expeced no error, got: %v", err)
+	}
+	expected := "http://open.mapquestapi.com/nominatim/v1/search.php?accept-language=fr-FR&addressdetails=1&format=json&q=Berlin"
+	if got != expected {
+		t.Errorf("expected %q, got: %q", expected, got)
+	}
+
+	got, err = client.Nominatim().buildSearchURL(&NominatimSearchRequest{Query: "Berlin", Language: "de"})
+	if err != nil {
+		t.Fatalf("expeced no error, got: %v", err)
+	}
+	expected = "http://open.mapquestapi.com/nominatim/v1/search.php?accept-language=de&addressdetails=1&format=json&q=Berlin"
+	if got != expected {
+		t.Errorf("expected %q, got: %q", expected, got)
+	}
+}
+
+func TestNominatimBuildSearchURLErrors(t *testing.T) {
+	bounded := true
+	routeWidth := 0.01
+	tests := []*NominatimSearchRequest{
+		{Query: "pharmacy", Bounded: &bounded},
+		{Query: "pharmacy", ViewBox: &GeoBox{A: GeoPoint{Latitude: 91}}},
+		{Query: "fuel", Route: []GeoPoint{{Latitude: 52.5, Longitude: 13.3}}},
+		{Query: "fuel", RouteWidth: &routeWidth},
+		{Query: "fuel", Route: []GeoPoint{{Latitude: 52.5, Longitude: 13.3}, {Latitude: 52.6, Longitude: 13.4}}},
+		{
+			Query:   "fuel",
+			ViewBox: &GeoBox{},
+			Route:   []GeoPoint{{Latitude: 52.5, Longitude: 13.3}, {Latitude: 52.6, Longitude: 13.4}},
+		},
+	}
+
+	client := NewClient("my-key")
+	for i, req := range tests {
+		if _, err := client.Nominatim().buildSearchURL(req); err == nil {
+			t.Errorf("case %d: expected error, got: nil", i)
+		}
+	}
+}
+
+func TestNominatimBuildReverseURLs(t *testing.T) {
+	tests := []struct {
+		Request *NominatimReverseRequest
+		URL     string
+	}{
+		{
+			Request: &NominatimReverseRequest{
+				Location: &GeoPoint{
+					Latitude:  52.5173324,
+					Longitude: 13.3932632,
+				},
+				Zoom: 18,
+			},
+			URL: "http://open.mapquestapi.com/nominatim/v1/reverse.php?addressdetails=1&format=json&lat=52.517332&lon=13.393263&zoom=18",
+		},
+		{
+			Request: &NominatimReverseRequest{
+				OSMType:        "W",
+				OSMId:          "110676319",
+				AddressDetails: new(bool),
+			},
+			URL: "http://open.mapquestapi.com/nominatim/v1/reverse.php?addressdetails=0&format=json&osm_id=110676319&osm_type=W",
+		},
+	}
+
+	client := NewClient("my-key")
+	for _, test := range tests {
+		got, err := client.Nominatim().buildReverseURL(test.Request)
+		if err != nil {
+			t.Fatalf("expeced no error, got: %v", err)
+		}
+		if got != test.URL {
+			t.Errorf("expected %q, got: %q", test.URL, got)
+		}
+	}
+
+	if _, err := client.Nominatim().buildReverseURL(&NominatimReverseRequest{}); err == nil {
+		t.Error("expected error for missing location, got: nil")
+	}
+}
+
+func TestNominatimReverse(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("lat") == "0.000000" {
+			w.Write([]byte(`{"error":"Unable to geocode"}`))
+			return
+		}
+		w.Write([]byte(`{"place_id":"70421736","osm_type":"way","osm_id":"110676319","lat":"52.5173324","lon":"13.3932632","display_name":"Unter den Linden, Berlin","address":{"road":"Unter den Linden","city":"Berlin"}}`))
+	}))
+	defer ts.Close()
+
+	client := newTestClient(t, ts)
+	res, err := client.Nominatim().Reverse(&NominatimReverseRequest{
+		Location: &GeoPoint{Latitude: 52.5173324, Longitude: 13.3932632},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if res.OSMId != "110676319" {
+		t.Errorf("expected OSM id %q, got: %q", "110676319", res.OSMId)
+	}
+	if res.Latitude != float64(52.5173324) {
+		t.Errorf("expected latitude %f, got: %f", float64(52.5173324), res.Latitude)
+	}
+	if res.Address == nil || res.Address.Road != "Unter den Linden" {
+		t.Errorf("expected road %q, got: %v", "Unter den Linden", res.Address)
+	}
+
+	_, err = client.Nominatim().Reverse(&NominatimReverseRequest{
+		Location: &GeoPoint{},
+	})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got: %v", err)
+	}
+	if len(apiErr.Messages) != 1 || apiErr.Messages[0] != "Unable to geocode" {
+		t.Errorf("expected message %q, got: %v", "Unable to geocode", apiErr.Messages)
+	}
+}
+
+func TestNominatimSearchResultDecoding(t *testing.T) {
+	data := `{
+		"place_id": "158947",
+		"boundingbox": ["52.3382448", "52.6755087", "13.0883450", "13.7611609"],
+		"lat": "52.5170365",
+		"lon": "13.3888599",
+		"geojson": {
+			"type": "Polygon",
+			"coordinates": [[[13.08, 52.33], [13.76, 52.33], [13.76, 52.67], [13.08, 52.33]]]
+		},
+		"extratags": {"wikidata": "Q64", "population": "3769495"},
+		"namedetails": {"name": "Berlin", "name:fr": "Berlin"}
+	}`
+	var res NominatimSearchResult
+	if err := json.Unmarshal([]byte(data), &res); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if res.PlaceId != "158947" {
+		t.Errorf("expected place id %q, got: %q", "158947", res.PlaceId)
+	}
+	expectedBox := &GeoBox{
+		A: GeoPoint{Latitude: 52.3382448, Longitude: 13.0883450},
+		B: GeoPoint{Latitude: 52.6755087, Longitude: 13.7611609},
+	}
+	if res.BoundingBox == nil || *res.BoundingBox != *expectedBox {
+		t.Errorf("expected bounding box %v, got: %v", expectedBox, res.BoundingBox)
+	}
+	if res.ExtraTags["wikidata"] != "Q64" {
+		t.Errorf("expected wikidata %q, got: %q", "Q64", res.ExtraTags["wikidata"])
+	}
+	if res.NameDetails["name:fr"] != "Berlin" {
+		t.Errorf("expected name:fr %q, got: %q", "Berlin", res.NameDetails["name:fr"])
+	}
+	if res.Geometry == nil {
+		t.Fatal("expected geometry, got: nil")
+	}
+	polygons, err := res.Geometry.Polygons()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(polygons) != 1 || len(polygons[0]) != 1 || len(polygons[0][0]) != 4 {
+		t.Fatalf("expected 1 polygon with 1 ring of 4 points, got: %v", polygons)
+	}
+	if pt := polygons[0][0][1]; pt.Latitude != 52.33 || pt.Longitude != 13.76 {
+		t.Errorf("expected point %v, got: %v", GeoPoint{Latitude: 52.33, Longitude: 13.76}, pt)
+	}
+}
+
+func TestNominatimAddressDecoding(t *testing.T) {
+	data := `{
+		"building": "Rathaus",
+		"road": "Hauptstraße",
+		"village": "Hinterzarten",
+		"ISO3166-2-lvl4": "DE-BW",
+		"amenity": "Townhall",
+		"country_code": "de"
+	}`
+	var addr NominatimAddress
+	if err := json.Unmarshal([]byte(data), &addr); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if addr.Building != "Rathaus" {
+		t.Errorf("expected building %q, got: %q", "Rathaus", addr.Building)
+	}
+	if addr.ISO3166Lvl4 != "DE-BW" {
+		t.Errorf("expected ISO3166-2-lvl4 %q, got: %q", "DE-BW", addr.ISO3166Lvl4)
+	}
+	if len(addr.Other) != 1 || addr.Other["amenity"] != "Townhall" {
+		t.Errorf("expected other components %v, got: %v", map[string]string{"amenity": "Townhall"}, addr.Other)
+	}
+	if got := addr.Locality(); got != "Hinterzarten" {
+		t.Errorf("expected locality %q, got: %q", "Hinterzarten", got)
+	}
+	if got := addr.Street(); got != "Hauptstraße" {
+		t.Errorf("expected street %q, got: %q", "Hauptstraße", got)
+	}
+
+	out, err := json.Marshal(&addr)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var all map[string]string
+	if err := json.Unmarshal(out, &all); err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 6 || all["amenity"] != "Townhall" {
+		t.Errorf("expected all components to round-trip, got: %s", out)
+	}
+}
+
+func TestNominatimBuildLookupURLs(t *testing.T) {
+	client := NewClient("my-key")
+	got, err := client.Nominatim().buildLookupURL(&NominatimLookupRequest{
+		OSMIds: []string{"N123", "W456", "R789"},
+	})
+	if err != nil {
+		t.Fatalf("expeced no error, got: %v", err)
+	}
+	expected := "http://open.mapquestapi.com/nominatim/v1/lookup.php?addressdetails=1&format=json&osm_ids=N123%2CW456%2CR789"
+	if got != expected {
+		t.Errorf("expected %q, got: %q", expected, got)
+	}
+
+	tooMany := make([]string, NominatimLookupLimit+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("N%d", i+1)
+	}
+	for _, ids := range [][]string{nil, {"123"}, {"X123"}, {"Nabc"}, {"W12a"}, {"R-1"}, tooMany} {
+		if _, err := client.Nominatim().buildLookupURL(&NominatimLookupRequest{OSMIds: ids}); err == nil {
+			t.Errorf("expected error for OSM ids %v, got: nil", ids)
+		}
+	}
+}
+
+func TestNominatimLookup(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/nominatim/v1/lookup.php" {
+			t.Errorf("expected path %q, got: %q", "/nominatim/v1/lookup.php", r.URL.Path)
+		}
+		// Some servers return ids as numbers, others as strings
+		w.Write([]byte(`[
+			{"place_id": 70421736, "osm_type": "way", "osm_id": 110676319},
+			{"place_id": "158947", "osm_type": "relation", "osm_id": "62422"}
+		]`))
+	}))
+	defer ts.Close()
+
+	client := newTestClient(t, ts)
+	res, err := client.Nominatim().Lookup(&NominatimLookupRequest{
+		OSMIds: []string{"W110676319", "R62422"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(res.Results) != 2 {
+		t.Fatalf("expected %d results, got: %d", 2, len(res.Results))
+	}
+	if res.Results[0].PlaceId != "70421736" || res.Results[0].OSMId != "110676319" {
+		t.Errorf("expected ids %q and %q, got: %q and %q", "70421736", "110676319", res.Results[0].PlaceId, res.Results[0].OSMId)
+	}
+	if res.Results[1].PlaceId != "158947" || res.Results[1].OSMId != "62422" {
+		t.Errorf("expected ids %q and %q, got: %q and %q", "158947", "62422", res.Results[1].PlaceId, res.Results[1].OSMId)
+	}
+}
+
+func TestNominatimLookupChunks(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		ids := strings.Split(r.URL.Query().Get("osm_ids"), ",")
+		if len(ids) > NominatimLookupLimit {
+			t.Errorf("expected at most %d ids, got: %d", NominatimLookupLimit, len(ids))
+		}
+		var results []*NominatimSearchResult
+		for _, id := range ids {
+			results = append(results, &NominatimSearchResult{OSMType: "node", OSMId: json.Number(id[1:])})
+		}
+		json.NewEncoder(w).Encode(results)
+	}))
+	defer ts.Close()
+
+	req := &NominatimLookupRequest{}
+	for i := 0; i < 120; i++ {
+		req.OSMIds = append(req.OSMIds, fmt.Sprintf("N%d", i+1))
+	}
+
+	client := newTestClient(t, ts)
+	res, err := client.Nominatim().Lookup(req)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 3 {
+		t.Errorf("expected %d requests, got: %d", 3, n)
+	}
+	if len(res.Results) != len(req.OSMIds) {
+		t.Fatalf("expected %d results, got: %d", len(req.OSMIds), len(res.Results))
+	}
+	for i, result := range res.Results {
+		if want := req.OSMIds[i][1:]; result.OSMId.String() != want {
+			t.Errorf("expected result %d to have OSM id %q, got: %q", i, want, result.OSMId)
+		}
+	}
+
+	req.OSMIds[110] = "Nabc"
+	if _, err := client.Nominatim().Lookup(req); err == nil {
+		t.Error("expected error for invalid OSM id, got: nil")
+	}
+	if n := atomic.LoadInt32(&calls); n != 3 {
+		t.Errorf("expected no requests for invalid OSM ids, got: %d", n-3)
+	}
+}
+
+func TestNominatimDetails(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/nominatim/v1/details.php" {
+			t.Errorf("expected path %q, got: %q", "/nominatim/v1/details.php", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("hierarchy"); got != "1" {
+			t.Errorf("expected hierarchy=1, got: %q", got)
+		}
+		w.Write([]byte(`{
+			"place_id": 70421736,
+			"osm_type": "W",
+			"osm_id": 110676319,
+			"category": "highway",
+			"type": "primary",
+			"localname": "Unter den Linden",
+			"names": {"name": "Unter den Linden"},
+			"centroid": {"type": "Point", "coordinates": [13.3932632, 52.5173324]},
+			"address": [
+				{"localname": "Unter den Linden", "place_id": 70421736, "osm_type": "W", "osm_id": 110676319, "isaddress": true, "rank_address": 26},
+				{"localname": "Berlin", "place_id": 158947, "osm_type": "R", "osm_id": 62422, "isaddress": true, "rank_address": 16, "admin_level": 4}
+			],
+			"hierarchy": {"building": [{"localname": "Humboldt-Universität", "place_id": 1234}]}
+		}`))
+	}))
+	defer ts.Close()
+
+	client := newTestClient(t, ts)
+	res, err := client.Nominatim().Details(&NominatimDetailsRequest{
+		OSMType:        "W",
+		OSMId:          "110676319",
+		AddressDetails: true,
+		Hierarchy:      true,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if res.PlaceId.String() != "70421736" {
+		t.Errorf("expected place id %q, got: %q", "70421736", res.PlaceId)
+	}
+	if res.LocalName != "Unter den Linden" {
+		t.Errorf("expected local name %q, got: %q", "Unter den Linden", res.LocalName)
+	}
+	if len(res.Address) != 2 || res.Address[1].LocalName != "Berlin" || res.Address[1].AdminLevel != 4 {
+		t.Errorf("expected 2 address lines, got: %v", res.Address)
+	}
+	if len(res.Hierarchy["building"]) != 1 {
+		t.Errorf("expected 1 building in hierarchy, got: %v", res.Hierarchy)
+	}
+	if res.Centroid == nil {
+		t.Fatal("expected centroid, got: nil")
+	}
+	pt, err := res.Centroid.Point()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if pt.Latitude != 52.5173324 || pt.Longitude != 13.3932632 {
+		t.Errorf("expected centroid %v, got: %v", GeoPoint{Latitude: 52.5173324, Longitude: 13.3932632}, pt)
+	}
+}
+
+func TestNominatimEmptyTags(t *testing.T) {
+	var details NominatimDetailsResult
+	data := `{"place_id":1,"names":[],"addresstags":[],"extratags":[]}`
+	if err := json.Unmarshal([]byte(data), &details); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(details.Names) != 0 || len(details.AddressTags) != 0 || len(details.ExtraTags) != 0 {
+		t.Errorf("expected no tags, got: %v, %v, %v", details.Names, details.AddressTags, details.ExtraTags)
+	}
+
+	var result NominatimSearchResult
+	data = `{"place_id":"1","extratags":[],"namedetails":{"name":"Berlin"}}`
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(result.ExtraTags) != 0 {
+		t.Errorf("expected no extra tags, got: %v", result.ExtraTags)
+	}
+	if result.NameDetails["name"] != "Berlin" {
+		t.Errorf("expected name %q, got: %q", "Berlin", result.NameDetails["name"])
+	}
+
+	if err := json.Unmarshal([]byte(`{"extratags":["a"]}`), &result); err == nil {
+		t.Error("expected error for non-empty array, got: nil")
+	}
+}
+
 func TestNominatimSearch(t *testing.T) {
 	key, err := readKey(t)
 	if err != nil {
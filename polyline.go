@@ -0,0 +1,125 @@
+package mapquest
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Precisions of encoded lines.
+const (
+	// PolylinePrecision is the precision of Google's encoded
+	// polyline format, i.e. 5 decimal places.
+	PolylinePrecision = 5
+
+	// ShapePrecisionCmp is the precision of MapQuest's compressed
+	// shape format "cmp", i.e. 5 decimal places.
+	ShapePrecisionCmp = 5
+
+	// ShapePrecisionCmp6 is the precision of MapQuest's compressed
+	// shape format "cmp6", i.e. 6 decimal places.
+	ShapePrecisionCmp6 = 6
+)
+
+// EncodePolyline encodes the line in Google's encoded polyline format
+// with the given number of decimal places, e.g. PolylinePrecision.
+// See https://developers.google.com/maps/documentation/utilities/polylinealgorithm
+// for details.
+func (l GeoLine) EncodePolyline(precision int) string {
+	factor := math.Pow10(precision)
+
+	var sb strings.Builder
+	var lastLat, lastLng int64
+	for _, pt := range l {
+		lat := int64(math.Round(pt.Latitude * factor))
+		lng := int64(math.Round(pt.Longitude * factor))
+		encodeSigned(&sb, lat-lastLat)
+		encodeSigned(&sb, lng-lastLng)
+		lastLat, lastLng = lat, lng
+	}
+	return sb.String()
+}
+
+// DecodePolyline decodes a line in Google's encoded polyline format
+// with the given number of decimal places, e.g. PolylinePrecision.
+func DecodePolyline(encoded string, precision int) (GeoLine, error) {
+	factor := math.Pow10(precision)
+
+	var line GeoLine
+	var lat, lng int64
+	for i := 0; i < len(encoded); {
+		dlat, n, err := decodeSigned(encoded[i:])
+		if err != nil {
+			return nil, err
+		}
+		i += n
+		dlng, n, err := decodeSigned(encoded[i:])
+		if err != nil {
+			return nil, err
+		}
+		i += n
+
+		lat += dlat
+		lng += dlng
+		line = append(line, GeoPoint{
+			Latitude:  float64(lat) / factor,
+			Longitude: float64(lng) / factor,
+		})
+	}
+	return line, nil
+}
+
+// EncodeShape encodes the line in MapQuest's compressed shape format
+// with the given number of decimal places, i.e. ShapePrecisionCmp or
+// ShapePrecisionCmp6. It uses the same algorithm as Google's encoded
+// polyline format.
+// See http://open.mapquestapi.com/common/encodedecode.html for details.
+func (l GeoLine) EncodeShape(precision int) string {
+	return l.EncodePolyline(precision)
+}
+
+// DecodeShape decodes a line in MapQuest's compressed shape format
+// with the given number of decimal places, i.e. ShapePrecisionCmp or
+// ShapePrecisionCmp6.
+func DecodeShape(encoded string, precision int) (GeoLine, error) {
+	return DecodePolyline(encoded, precision)
+}
+
+// encodeSigned writes the encoded representation of v to sb.
+func encodeSigned(sb *strings.Builder, v int64) {
+	u := uint64(v) << 1
+	if v < 0 {
+		u = ^u
+	}
+	for u >= 0x20 {
+		sb.WriteByte(byte(0x20|(u&0x1f)) + 63)
+		u >>= 5
+	}
+	sb.WriteByte(byte(u) + 63)
+}
+
+// decodeSigned decodes the value at the beginning of s and returns
+// it together with the number of bytes consumed.
+func decodeSigned(s string) (int64, int, error) {
+	var u uint64
+	var shift uint
+	for i := 0; i < len(s); i++ {
+		b := int(s[i]) - 63
+		if b < 0 || b > 0x3f {
+			return 0, 0, fmt.Errorf("mapquest: invalid character %q in encoded line", s[i])
+		}
+		if shift > 63 {
+			return 0, 0, fmt.Errorf("mapquest: value overflow in encoded line")
+		}
+		u |= uint64(b&0x1f) << shift
+		shift += 5
+		if b < 0x20 {
+			v := int64(u >> 1)
+			if u&1 != 0 {
+				v = ^v
+			}
+			return v, i + 1, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("mapquest: unexpected end of encoded line")
+}
@@ -0,0 +1,84 @@
+package mapquest
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEncodePolyline(t *testing.T) {
+	// Example from Google's documentation of the algorithm
+	line := GeoLine{
+		{Latitude: 38.5, Longitude: -120.2},
+		{Latitude: 40.7, Longitude: -120.95},
+		{Latitude: 43.252, Longitude: -126.453},
+	}
+	expected := "_p~iF~ps|U_ulLnnqC_mqNvxq`@"
+	if got := line.EncodePolyline(PolylinePrecision); got != expected {
+		t.Errorf("expected %q, got: %q", expected, got)
+	}
+
+	decoded, err := DecodePolyline(expected, PolylinePrecision)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(decoded) != len(line) {
+		t.Fatalf("expected %d points, got: %d", len(line), len(decoded))
+	}
+	for i := range line {
+		if !closeTo(decoded[i], line[i], 1e-5) {
+			t.Errorf("expected point %d to be %v, got: %v", i, line[i], decoded[i])
+		}
+	}
+}
+
+func TestEncodeShapeRoundTrip(t *testing.T) {
+	line := GeoLine{
+		{Latitude: 39.952584, Longitude: -75.165222},
+		{Latitude: 39.953123, Longitude: -75.160001},
+		{Latitude: -33.868820, Longitude: 151.209296},
+		{Latitude: 0, Longitude: 0},
+	}
+	for _, precision := range []int{ShapePrecisionCmp, ShapePrecisionCmp6} {
+		encoded := line.EncodeShape(precision)
+		decoded, err := DecodeShape(encoded, precision)
+		if err != nil {
+			t.Fatalf("precision %d: expected no error, got: %v", precision, err)
+		}
+		if len(decoded) != len(line) {
+			t.Fatalf("precision %d: expected %d points, got: %d", precision, len(line), len(decoded))
+		}
+		for i := range line {
+			if !closeTo(decoded[i], line[i], math.Pow10(-precision)) {
+				t.Errorf("precision %d: expected point %d to be %v, got: %v", precision, i, line[i], decoded[i])
+			}
+		}
+	}
+}
+
+func TestDecodePolylineErrors(t *testing.T) {
+	for _, encoded := range []string{"_p~iF~ps|U_", "_p~iF", "_p~iF\x01"} {
+		if _, err := DecodePolyline(encoded, PolylinePrecision); err == nil {
+			t.Errorf("%q: expected error, got: nil", encoded)
+		}
+	}
+}
+
+func TestGeoLineFlat(t *testing.T) {
+	line, err := GeoLineFromFlat([]float64{1, 2, 3, 4})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(line) != 2 || line[1] != (GeoPoint{Latitude: 3, Longitude: 4}) {
+		t.Errorf("expected 2 points, got: %v", line)
+	}
+	if flat := line.Flat(); len(flat) != 4 || flat[2] != 3 {
+		t.Errorf("expected flat coordinates [1 2 3 4], got: %v", flat)
+	}
+	if _, err := GeoLineFromFlat([]float64{1, 2, 3}); err == nil {
+		t.Error("expected error for uneven number of coordinates, got: nil")
+	}
+}
+
+func closeTo(a, b GeoPoint, eps float64) bool {
+	return math.Abs(a.Latitude-b.Latitude) <= eps && math.Abs(a.Longitude-b.Longitude) <= eps
+}
@@ -0,0 +1,157 @@
+package mapquest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned when a request is rejected by a
+// RateLimiter in RateLimitFailFast mode.
+var ErrRateLimited = errors.New("mapquest: client-side rate limit exceeded")
+
+// Service identifies one of the MapQuest services. It is used e.g.
+// to configure a rate limiter for a specific service.
+type Service string
+
+const (
+	ServiceGeocoding  Service = "geocoding"
+	ServiceNominatim  Service = "nominatim"
+	ServiceStaticMap  Service = "staticmap"
+	ServiceDirections Service = "directions"
+	ServiceElevation  Service = "elevation"
+)
+
+// RateLimitMode specifies what a RateLimiter does when the limit is hit.
+type RateLimitMode int
+
+const (
+	// RateLimitWait blocks until the request is allowed, or until the
+	// context of the request is done.
+	RateLimitWait RateLimitMode = iota
+
+	// RateLimitFailFast rejects the request with ErrRateLimited.
+	RateLimitFailFast
+)
+
+// RateLimiter is a token bucket limiting the number of requests sent
+// to MapQuest. It is safe for concurrent use, so it can be shared by
+// many goroutines and even by many clients.
+//
+// Use Client.SetRateLimiter or Client.SetServiceRateLimiter to
+// enable rate limiting. By default, the client does not limit requests.
+type RateLimiter struct {
+	rate  float64 // tokens per second
+	burst float64
+	mode  RateLimitMode
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a new RateLimiter that allows rps requests
+// per second on average, with bursts of up to burst requests.
+// The mode specifies what to do when the limit is hit. If rps is
+// zero or negative, all requests are allowed.
+func NewRateLimiter(rps float64, burst int, mode RateLimitMode) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   rps,
+		burst:  float64(burst),
+		mode:   mode,
+		tokens: float64(burst),
+	}
+}
+
+// Wait takes a token from the bucket. In RateLimitWait mode, it blocks
+// until a token is available. It returns early with an error if ctx is
+// done, or if ctx has a deadline that expires before the token would be
+// available. In RateLimitFailFast mode, it returns ErrRateLimited
+// immediately if no token is available.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l.rate <= 0 {
+		return nil
+	}
+	if l.mode == RateLimitFailFast {
+		if !l.Allow() {
+			return ErrRateLimited
+		}
+		return nil
+	}
+
+	now := time.Now()
+	wait := l.reserve(now)
+	if wait <= 0 {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(now.Add(wait)) {
+		l.cancel()
+		return context.DeadlineExceeded
+	}
+	if err := sleep(ctx, wait); err != nil {
+		l.cancel()
+		return err
+	}
+	return nil
+}
+
+// Allow takes a token from the bucket and returns true if one is
+// available. It never blocks.
+func (l *RateLimiter) Allow() bool {
+	if l.rate <= 0 {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(time.Now())
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// reserve takes a token from the bucket and returns the time to wait
+// until the token is actually available.
+func (l *RateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(now)
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// cancel returns a reserved token to the bucket.
+func (l *RateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens++
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
+
+// refill adds the tokens accumulated since the last call.
+// The caller must hold l.mu.
+func (l *RateLimiter) refill(now time.Time) {
+	if l.last.IsZero() {
+		l.last = now
+		return
+	}
+	// Callers read the clock before taking the lock, so now may
+	// be before l.last; moving l.last back would credit time twice.
+	if now.After(l.last) {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+		l.last = now
+	}
+}
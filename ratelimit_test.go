@@ -0,0 +1,94 @@
+package mapquest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	l := NewRateLimiter(1, 2, RateLimitFailFast)
+	if !l.Allow() {
+		t.Error("expected first request to be allowed")
+	}
+	if !l.Allow() {
+		t.Error("expected second request to be allowed within burst")
+	}
+	if l.Allow() {
+		t.Error("expected third request to be rejected")
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	l := NewRateLimiter(50, 1, RateLimitWait)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("expected to wait at least %v, got: %v", 30*time.Millisecond, elapsed)
+	}
+}
+
+func TestRateLimiterWaitDeadline(t *testing.T) {
+	l := NewRateLimiter(0.1, 1, RateLimitWait)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := l.Wait(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected %v, got: %v", context.DeadlineExceeded, err)
+	}
+	// The next token is 10s away, so anything well below that
+	// means Wait did not block until the token became available.
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected to fail without waiting for a token, got: %v", elapsed)
+	}
+}
+
+func TestRateLimiterOutOfOrderReservations(t *testing.T) {
+	l := NewRateLimiter(1, 1, RateLimitWait)
+	now := time.Now()
+
+	// A goroutine that read the clock earlier may reserve later
+	if wait := l.reserve(now); wait != 0 {
+		t.Fatalf("expected no wait, got: %v", wait)
+	}
+	if wait := l.reserve(now.Add(-time.Second)); wait <= 0 {
+		t.Fatalf("expected to wait, got: %v", wait)
+	}
+	if wait := l.reserve(now); wait < 2*time.Second {
+		t.Errorf("expected to wait at least %v, got: %v", 2*time.Second, wait)
+	}
+}
+
+func TestClientServiceRateLimiter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+	defer ts.Close()
+
+	c := newTestClient(t, ts)
+	c.SetRateLimiter(NewRateLimiter(100, 10, RateLimitFailFast))
+	c.SetServiceRateLimiter(ServiceNominatim, NewRateLimiter(1, 1, RateLimitFailFast))
+
+	req := &NominatimSearchRequest{Query: "Berlin"}
+	if _, err := c.Nominatim().Search(req); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := c.Nominatim().Search(req); err != ErrRateLimited {
+		t.Fatalf("expected %v, got: %v", ErrRateLimited, err)
+	}
+
+	c.SetServiceRateLimiter(ServiceNominatim, nil)
+	if _, err := c.Nominatim().Search(req); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package mapquest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy specifies how the client retries requests that failed
+// with a transient error, e.g. a connection reset, a 5xx status code,
+// or a 429 (Too Many Requests). Only GET requests are retried, which
+// is all that the MapQuest APIs use, so retrying is always safe.
+//
+// Use Client.SetRetryPolicy to enable retries. By default, the client
+// does not retry.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts per request,
+	// including the first one. Values less than 2 disable retries.
+	MaxAttempts int
+
+	// InitialBackoff is the time to wait before the first retry.
+	// It is doubled on every subsequent retry.
+	InitialBackoff time.Duration
+
+	// MaxBackoff limits the time to wait between two attempts.
+	// It does not limit the time specified by a Retry-After header.
+	MaxBackoff time.Duration
+
+	// Jitter is the fraction of the backoff, in the range of 0 to 1,
+	// that is randomized to avoid many clients retrying in lockstep.
+	// E.g. with a Jitter of 0.5, a backoff of 2s results in a wait
+	// time between 1s and 2s.
+	Jitter float64
+
+	// ShouldRetry decides whether to retry a request, given either
+	// the response or the error of the last attempt. If nil,
+	// IsRetryable is used.
+	ShouldRetry func(res *http.Response, err error) bool
+
+	// OnRetry is called before waiting for the next attempt. The
+	// attempt is the number of the attempt that failed, starting at 1.
+	// It can be used e.g. to log or to count retries.
+	OnRetry func(attempt int, wait time.Duration, res *http.Response, err error)
+}
+
+// DefaultRetryPolicy returns a RetryPolicy with sensible defaults:
+// Up to 4 attempts, starting with a backoff of 500ms, up to 10s.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Jitter:         0.5,
+	}
+}
+
+// IsRetryable returns true if res or err indicate a transient failure,
+// i.e. a network error, a 429 (Too Many Requests) or a 5xx status code
+// other than 501 (Not Implemented).
+func IsRetryable(res *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	if res == nil {
+		return false
+	}
+	switch res.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func (p *RetryPolicy) shouldRetry(attempt int, req *http.Request, res *http.Response, err error) bool {
+	if p == nil || attempt >= p.MaxAttempts || req.Method != "GET" {
+		return false
+	}
+	if p.ShouldRetry != nil {
+		return p.ShouldRetry(res, err)
+	}
+	return IsRetryable(res, err)
+}
+
+// backoff returns the time to wait after the given failed attempt.
+func (p *RetryPolicy) backoff(attempt int, res *http.Response) time.Duration {
+	if d, ok := retryAfter(res); ok {
+		return d
+	}
+	d := float64(p.InitialBackoff) * math.Pow(2, float64(attempt-1))
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		d -= d * jitter * rand.Float64()
+	}
+	return time.Duration(d)
+}
+
+// retryAfter parses the Retry-After header of res, which is
+// either in seconds or a HTTP date.
+func retryAfter(res *http.Response) (time.Duration, bool) {
+	if res == nil {
+		return 0, false
+	}
+	v := res.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// sleep waits for d or until ctx is done, whichever comes first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// drain reads the remaining body of res and closes it,
+// so that the underlying connection can be reused.
+func drain(res *http.Response) {
+	if res == nil || res.Body == nil {
+		return
+	}
+	io.Copy(ioutil.Discard, io.LimitReader(res.Body, 64<<10))
+	res.Body.Close()
+}
@@ -0,0 +1,119 @@
+package mapquest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyRetriesTransientFailures(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer ts.Close()
+
+	var retries []int
+	c := newTestClient(t, ts)
+	c.SetRetryPolicy(&RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		OnRetry: func(attempt int, wait time.Duration, res *http.Response, err error) {
+			retries = append(retries, attempt)
+		},
+	})
+
+	_, err := c.Nominatim().Search(&NominatimSearchRequest{Query: "Berlin"})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 3 {
+		t.Errorf("expected %d calls, got: %d", 3, n)
+	}
+	if len(retries) != 2 || retries[0] != 1 || retries[1] != 2 {
+		t.Errorf("expected retries after attempts [1 2], got: %v", retries)
+	}
+}
+
+func TestRetryPolicyGivesUp(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	c := newTestClient(t, ts)
+	c.SetRetryPolicy(&RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond})
+
+	_, err := c.Nominatim().Search(&NominatimSearchRequest{Query: "Berlin"})
+	if err == nil {
+		t.Fatal("expected error, got: nil")
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("expected %d calls, got: %d", 2, n)
+	}
+}
+
+func TestRetryPolicyDoesNotRetryClientErrors(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	c := newTestClient(t, ts)
+	c.SetRetryPolicy(&RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+
+	_, err := c.Nominatim().Search(&NominatimSearchRequest{Query: "Berlin"})
+	if err == nil {
+		t.Fatal("expected error, got: nil")
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("expected %d call, got: %d", 1, n)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := &RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     300 * time.Millisecond,
+	}
+	tests := []struct {
+		Attempt  int
+		Expected time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 300 * time.Millisecond},
+		{10, 300 * time.Millisecond},
+	}
+	for _, test := range tests {
+		got := p.backoff(test.Attempt, nil)
+		if got != test.Expected {
+			t.Errorf("attempt %d: expected %v, got: %v", test.Attempt, test.Expected, got)
+		}
+	}
+
+	res := &http.Response{Header: http.Header{}}
+	res.Header.Set("Retry-After", "7")
+	if got := p.backoff(1, res); got != 7*time.Second {
+		t.Errorf("expected Retry-After of %v, got: %v", 7*time.Second, got)
+	}
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		got := p.backoff(2, nil)
+		if got < 100*time.Millisecond || got > 200*time.Millisecond {
+			t.Fatalf("expected backoff with jitter between %v and %v, got: %v",
+				100*time.Millisecond, 200*time.Millisecond, got)
+		}
+	}
+}
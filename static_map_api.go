@@ -1,13 +1,21 @@
 package mapquest
 
 import (
+	"context"
 	"fmt"
 	"image"
 	_ "image/gif"
 	_ "image/jpeg"
 	_ "image/png"
+	"io"
+	"io/ioutil"
 	"log"
+	"math"
+	"mime"
+	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -16,6 +24,40 @@ var _ = log.Print
 const (
 	// StaticMapPathPrefix is the default path prefix for the Static Map API.
 	StaticMapPathPrefix = "/staticmap/v4"
+
+	// StaticMapV5PathPrefix is the default path prefix for version 5
+	// of the Static Map API, which is used for requests with markers.
+	StaticMapV5PathPrefix = "/staticmap/v5"
+
+	// StaticMapRadiusMeters specifies the radius of a circle in meters.
+	StaticMapRadiusMeters = "meters"
+	// StaticMapRadiusMiles specifies the radius of a circle in miles.
+	StaticMapRadiusMiles = "miles"
+)
+
+const (
+	// earthRadius is the mean radius of the earth in meters.
+	earthRadius = 6371008.8
+	// metersPerMile is the number of meters in a statute mile.
+	metersPerMile = 1609.344
+)
+
+const (
+	// MarkerShapeMarker is the default marker shape.
+	MarkerShapeMarker = "marker"
+	// MarkerShapeCircle is a circle-shaped marker.
+	MarkerShapeCircle = "circle"
+	// MarkerShapeFlag is a flag-shaped marker that fits longer labels.
+	MarkerShapeFlag = "flag"
+	// MarkerShapeVia is a small marker for via points of a route.
+	MarkerShapeVia = "via"
+
+	// MarkerSizeSmall is a small marker.
+	MarkerSizeSmall = "sm"
+	// MarkerSizeMedium is a medium-sized marker.
+	MarkerSizeMedium = "md"
+	// MarkerSizeLarge is a large marker.
+	MarkerSizeLarge = "lg"
 )
 
 // StaticMapAPI enables users to request static map images via the
@@ -25,29 +67,138 @@ type StaticMapAPI struct {
 }
 
 // Get returns an image of static map by querying MapQuest.
+// It returns an *APIError if MapQuest responds with something
+// other than an image, e.g. an HTML error page.
 func (api *StaticMapAPI) Get(req *StaticMapRequest) (image.Image, error) {
-	u, err := api.buildURL(req)
+	return api.GetContext(context.Background(), req)
+}
+
+// GetContext is like Get, but binds the request to ctx.
+// Cancelling ctx aborts the request to MapQuest.
+func (api *StaticMapAPI) GetContext(ctx context.Context, req *StaticMapRequest) (image.Image, error) {
+	res, err := api.get(ctx, req)
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
 
-	res, err := api.c.getResponse(u)
+	img, _, err := image.Decode(res.Body)
 	if err != nil {
 		return nil, err
 	}
+
+	return img, nil
+}
+
+// GetRaw returns the static map image as returned by MapQuest, i.e.
+// without decoding it, along with its MIME type, e.g. "image/png".
+func (api *StaticMapAPI) GetRaw(req *StaticMapRequest) ([]byte, string, error) {
+	return api.GetRawContext(context.Background(), req)
+}
+
+// GetRawContext is like GetRaw, but binds the request to ctx.
+func (api *StaticMapAPI) GetRawContext(ctx context.Context, req *StaticMapRequest) ([]byte, string, error) {
+	res, err := api.get(ctx, req)
+	if err != nil {
+		return nil, "", err
+	}
 	defer res.Body.Close()
 
-	img, _, err := image.Decode(res.Body)
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, imageContentType(res), nil
+}
+
+// Write writes the static map image as returned by MapQuest to w and
+// returns its MIME type, e.g. "image/png".
+func (api *StaticMapAPI) Write(w io.Writer, req *StaticMapRequest) (string, error) {
+	return api.WriteContext(context.Background(), w, req)
+}
+
+// WriteContext is like Write, but binds the request to ctx.
+func (api *StaticMapAPI) WriteContext(ctx context.Context, w io.Writer, req *StaticMapRequest) (string, error) {
+	res, err := api.get(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if _, err := io.Copy(w, res.Body); err != nil {
+		return "", err
+	}
+	return imageContentType(res), nil
+}
+
+// get requests the static map and makes sure the response is an image.
+func (api *StaticMapAPI) get(ctx context.Context, req *StaticMapRequest) (*http.Response, error) {
+	u, err := api.buildURL(req)
 	if err != nil {
 		return nil, err
 	}
 
-	return img, nil
+	res, err := api.c.getResponse(ctx, ServiceStaticMap, u)
+	if err != nil {
+		return nil, err
+	}
+
+	// MapQuest sometimes returns error pages with a status of 200 OK
+	if ct := imageContentType(res); ct != "" && !strings.HasPrefix(ct, "image/") {
+		defer res.Body.Close()
+		apiErr := &APIError{
+			StatusCode: res.StatusCode,
+			URL:        redactURL(u),
+			Messages:   []string{fmt.Sprintf("unexpected content type %q", ct)},
+		}
+		body, _ := ioutil.ReadAll(io.LimitReader(res.Body, 4096))
+		if msg := strings.TrimSpace(string(body)); msg != "" && !strings.HasPrefix(msg, "<") {
+			apiErr.Messages = append(apiErr.Messages, msg)
+		}
+		return nil, apiErr
+	}
+
+	return res, nil
+}
+
+// imageContentType returns the MIME type of the response without
+// parameters, e.g. "image/png".
+func imageContentType(res *http.Response) string {
+	ct := res.Header.Get("Content-Type")
+	if mt, _, err := mime.ParseMediaType(ct); err == nil {
+		return mt
+	}
+	return ct
+}
+
+// URL returns the URL of the static map image, e.g. to be used as
+// the source of an image in a web page. Notice that the URL includes
+// the key; use URLWithKey to omit or replace it.
+func (api *StaticMapAPI) URL(req *StaticMapRequest) (string, error) {
+	return api.buildURL(req)
+}
+
+// URLWithKey is like URL, but uses the given key instead of the key of
+// the client. If key is empty, the URL has no key parameter at all.
+// Use it e.g. with a placeholder that a signing proxy replaces with
+// the actual key. Notice that key is added to the URL without escaping.
+func (api *StaticMapAPI) URLWithKey(req *StaticMapRequest, key string) (string, error) {
+	return api.buildKeyURL(req, key)
 }
 
 // buildURL returns the complete URL for the request,
 // including the key to query the MapQuest API.
 func (api *StaticMapAPI) buildURL(req *StaticMapRequest) (string, error) {
+	return api.buildKeyURL(req, api.c.key)
+}
+
+// buildKeyURL returns the complete URL for the request with the
+// given key. The key parameter is omitted if key is empty.
+func (api *StaticMapAPI) buildKeyURL(req *StaticMapRequest, key string) (string, error) {
+	if len(req.Markers) > 0 {
+		return api.buildV5URL(req, key)
+	}
+
 	urls := fmt.Sprintf("%s%s/getmap", api.c.BaseURL(), StaticMapPathPrefix)
 	u, err := url.Parse(urls)
 	if err != nil {
@@ -102,10 +253,145 @@ func (api *StaticMapAPI) buildURL(req *StaticMapRequest) (string, error) {
 		}
 		qs = append(qs, fmt.Sprintf("pois=%s", strings.Join(parts, "|")))
 	}
+	for _, line := range req.Lines {
+		param, err := line.param(false)
+		if err != nil {
+			return "", err
+		}
+		qs = append(qs, fmt.Sprintf("polyline=%s", param))
+	}
+	for _, polygon := range req.Polygons {
+		param, err := polygon.param(true)
+		if err != nil {
+			return "", err
+		}
+		qs = append(qs, fmt.Sprintf("polygon=%s", param))
+	}
+	for _, rect := range req.Rectangles {
+		param, err := rect.param()
+		if err != nil {
+			return "", err
+		}
+		qs = append(qs, fmt.Sprintf("polygon=%s", param))
+	}
+	for _, circle := range req.Circles {
+		param, err := circle.param()
+		if err != nil {
+			return "", err
+		}
+		qs = append(qs, fmt.Sprintf("ellipse=%s", param))
+	}
 
 	// Key has to be handled specifically here, because
 	// the MapQuest API seems to not like the key URL-encoded
-	qs = append(qs, fmt.Sprintf("key=%s", api.c.key)) // Do not escape, MapQuest won't like it
+	if key != "" {
+		qs = append(qs, fmt.Sprintf("key=%s", key)) // Do not escape, MapQuest won't like it
+	}
+	u.RawQuery = strings.Join(qs, "&")
+	return u.String(), nil
+}
+
+// buildV5URL returns the URL of a request to the v5 static map API,
+// which is required for markers. The key parameter is omitted if
+// key is empty.
+func (api *StaticMapAPI) buildV5URL(req *StaticMapRequest, key string) (string, error) {
+	// Parameters of the v4 API without an equivalent in v5
+	switch {
+	case len(req.PointsOfInterest) > 0:
+		return "", fmt.Errorf("mapquest: markers cannot be combined with points of interest; use markers only")
+	case req.Scale > 0:
+		return "", fmt.Errorf("mapquest: markers cannot be combined with scale; use zoom instead")
+	}
+
+	urls := fmt.Sprintf("%s%s/map", api.c.BaseURL(), StaticMapV5PathPrefix)
+	u, err := url.Parse(urls)
+	if err != nil {
+		return "", err
+	}
+
+	// Add key and other parameters to the query string
+	qs := make([]string, 0)
+
+	if req.Center != nil {
+		pt := *req.Center
+		qs = append(qs, fmt.Sprintf("center=%f,%f", pt.Latitude, pt.Longitude))
+	}
+	if req.Bestfit != nil {
+		// The corners of a GeoBox can be in any order,
+		// but MapQuest wants the upper left and lower right
+		box := *req.Bestfit
+		qs = append(qs, fmt.Sprintf("boundingBox=%f,%f,%f,%f",
+			math.Max(box.A.Latitude, box.B.Latitude),
+			math.Min(box.A.Longitude, box.B.Longitude),
+			math.Min(box.A.Latitude, box.B.Latitude),
+			math.Max(box.A.Longitude, box.B.Longitude)))
+	}
+	if req.Margin > 0 {
+		qs = append(qs, fmt.Sprintf("margin=%d", req.Margin))
+	}
+	qs = append(qs, fmt.Sprintf("size=%d,%d", req.Width, req.Height))
+	if req.Zoom > 0 {
+		qs = append(qs, fmt.Sprintf("zoom=%d", req.Zoom))
+	}
+	if req.Type != "" {
+		qs = append(qs, fmt.Sprintf("type=%s", url.QueryEscape(req.Type)))
+	}
+	if req.Format != "" {
+		qs = append(qs, fmt.Sprintf("format=%s", url.QueryEscape(req.Format)))
+	}
+	for _, line := range req.Lines {
+		param, err := line.v5Param(false)
+		if err != nil {
+			return "", err
+		}
+		qs = append(qs, fmt.Sprintf("shape=%s", param))
+	}
+	for _, polygon := range req.Polygons {
+		param, err := polygon.v5Param(true)
+		if err != nil {
+			return "", err
+		}
+		qs = append(qs, fmt.Sprintf("shape=%s", param))
+	}
+	for _, rect := range req.Rectangles {
+		param, err := rect.v5Param()
+		if err != nil {
+			return "", err
+		}
+		qs = append(qs, fmt.Sprintf("shape=%s", param))
+	}
+	for _, circle := range req.Circles {
+		param, err := circle.v5Param()
+		if err != nil {
+			return "", err
+		}
+		qs = append(qs, fmt.Sprintf("shape=%s", param))
+	}
+	if len(req.Markers) > 0 {
+		markers := make([]*StaticMapMarker, len(req.Markers))
+		copy(markers, req.Markers)
+		sort.SliceStable(markers, func(i, j int) bool {
+			return markers[i].ZIndex < markers[j].ZIndex
+		})
+		locations := make([]string, len(markers))
+		for i, m := range markers {
+			if !m.Location.valid() {
+				return "", fmt.Errorf("mapquest: invalid marker location %v", m.Location)
+			}
+			style, err := m.style()
+			if err != nil {
+				return "", err
+			}
+			locations[i] = fmt.Sprintf("%f,%f|%s", m.Location.Latitude, m.Location.Longitude, style)
+		}
+		qs = append(qs, fmt.Sprintf("locations=%s", strings.Join(locations, "||")))
+	}
+
+	// Key has to be handled specifically here, because
+	// the MapQuest API seems to not like the key URL-encoded
+	if key != "" {
+		qs = append(qs, fmt.Sprintf("key=%s", key)) // Do not escape, MapQuest won't like it
+	}
 	u.RawQuery = strings.Join(qs, "&")
 	return u.String(), nil
 }
@@ -153,6 +439,322 @@ type StaticMapRequest struct {
 	// PointsOfInterest enlists the various points of interest to be
 	// displayed on the map.
 	PointsOfInterest []*PointOfInterest
+
+	// Lines to draw on the map, e.g. routes.
+	Lines []*StaticMapShape
+
+	// Polygons to draw on the map, e.g. delivery zones.
+	Polygons []*StaticMapShape
+
+	// Circles to draw on the map, e.g. service radiuses.
+	Circles []*StaticMapCircle
+
+	// Rectangles to draw on the map.
+	Rectangles []*StaticMapRectangle
+
+	// Markers to display on the map. Markers are only supported by
+	// version 5 of the Static Map API, so requests with markers are
+	// sent to StaticMapV5PathPrefix, with lines, polygons, rectangles,
+	// and circles as shapes. They cannot be combined with
+	// PointsOfInterest or Scale.
+	Markers []*StaticMapMarker
+}
+
+// StaticMapShape is a line or a polygon to draw on a map.
+type StaticMapShape struct {
+	// Points of the shape. A line needs at least 2 points,
+	// a polygon at least 3. Polygons are closed automatically.
+	Points GeoLine
+
+	// Color of the stroke as hex RGB, e.g. "FF0000" or "#FF0000".
+	// It is optional.
+	Color string
+
+	// Opacity of the stroke in the range of 0 (transparent) to 1
+	// (opaque). The default is opaque.
+	Opacity *float64
+
+	// Width of the stroke in pixels. It is optional.
+	Width int
+
+	// FillColor of a polygon as hex RGB, e.g. "00FF00".
+	// It is optional and ignored for lines.
+	FillColor string
+
+	// FillOpacity of a polygon in the range of 0 (transparent) to 1
+	// (opaque). The default is opaque. It is ignored for lines.
+	FillOpacity *float64
+}
+
+// param returns the shape in the format of the polyline and
+// polygon parameters, e.g. "color:0xFF0000|width:3|lat,lng,lat,lng".
+func (s *StaticMapShape) param(polygon bool) (string, error) {
+	if err := s.validate(polygon); err != nil {
+		return "", err
+	}
+
+	parts, err := staticMapStyle(s.Color, s.Opacity, s.Width, s.FillColor, s.FillOpacity, polygon, false)
+	if err != nil {
+		return "", err
+	}
+	coords, err := staticMapCoords(s.Points)
+	if err != nil {
+		return "", err
+	}
+	parts = append(parts, coords)
+
+	return strings.Join(parts, "|"), nil
+}
+
+// v5Param returns the shape in the format of the shape parameter of
+// version 5 of the API, e.g. "border:FF0000|width:3|lat,lng|lat,lng".
+func (s *StaticMapShape) v5Param(polygon bool) (string, error) {
+	if err := s.validate(polygon); err != nil {
+		return "", err
+	}
+
+	parts, err := staticMapStyle(s.Color, s.Opacity, s.Width, s.FillColor, s.FillOpacity, polygon, true)
+	if err != nil {
+		return "", err
+	}
+	points := s.Points
+	if polygon && points[0] != points[len(points)-1] {
+		// Close the polygon explicitly
+		points = append(points[:len(points):len(points)], points[0])
+	}
+	for _, pt := range points {
+		coords, err := staticMapCoords(GeoLine{pt})
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, coords)
+	}
+
+	return strings.Join(parts, "|"), nil
+}
+
+// validate checks the number of points of a line or polygon.
+func (s *StaticMapShape) validate(polygon bool) error {
+	min := 2
+	if polygon {
+		min = 3
+	}
+	if len(s.Points) < min {
+		return fmt.Errorf("mapquest: shape requires at least %d points, got %d", min, len(s.Points))
+	}
+	return nil
+}
+
+// StaticMapCircle is a circle to draw on a map, e.g. to visualize
+// the service radius of a store.
+type StaticMapCircle struct {
+	// Center of the circle.
+	Center GeoPoint
+
+	// Radius of the circle in the given Unit.
+	Radius float64
+
+	// Unit of the radius: StaticMapRadiusMeters (the default) or
+	// StaticMapRadiusMiles.
+	Unit string
+
+	// Color of the stroke as hex RGB, e.g. "FF0000". It is optional.
+	Color string
+
+	// Opacity of the stroke in the range of 0 (transparent) to 1
+	// (opaque). The default is opaque.
+	Opacity *float64
+
+	// Width of the stroke in pixels. It is optional.
+	Width int
+
+	// FillColor of the circle as hex RGB, e.g. "00FF00". It is optional.
+	FillColor string
+
+	// FillOpacity of the circle in the range of 0 (transparent) to 1
+	// (opaque). The default is opaque.
+	FillOpacity *float64
+}
+
+// param returns the circle in the format of the ellipse parameter,
+// i.e. the style followed by the upper left and lower right corners
+// of the bounding box of the circle.
+func (c *StaticMapCircle) param() (string, error) {
+	meters, err := c.meters()
+	if err != nil {
+		return "", err
+	}
+
+	dlat := meters / earthRadius * 180 / math.Pi
+	dlng := dlat / math.Cos(c.Center.Latitude*math.Pi/180)
+	box := GeoLine{
+		{Latitude: c.Center.Latitude + dlat, Longitude: c.Center.Longitude - dlng},
+		{Latitude: c.Center.Latitude - dlat, Longitude: c.Center.Longitude + dlng},
+	}
+
+	parts, err := staticMapStyle(c.Color, c.Opacity, c.Width, c.FillColor, c.FillOpacity, true, false)
+	if err != nil {
+		return "", err
+	}
+	coords, err := staticMapCoords(box)
+	if err != nil {
+		return "", fmt.Errorf("mapquest: circle radius %v %s is too large", c.Radius, c.Unit)
+	}
+	parts = append(parts, coords)
+	return strings.Join(parts, "|"), nil
+}
+
+// v5Param returns the circle in the format of the shape parameter
+// of version 5 of the API, e.g. "border:FF0000|radius:1.5km|lat,lng".
+func (c *StaticMapCircle) v5Param() (string, error) {
+	meters, err := c.meters()
+	if err != nil {
+		return "", err
+	}
+
+	parts, err := staticMapStyle(c.Color, c.Opacity, c.Width, c.FillColor, c.FillOpacity, true, true)
+	if err != nil {
+		return "", err
+	}
+	parts = append(parts,
+		"radius:"+strconv.FormatFloat(meters/1000, 'f', -1, 64)+"km",
+		fmt.Sprintf("%f,%f", c.Center.Latitude, c.Center.Longitude))
+	return strings.Join(parts, "|"), nil
+}
+
+// meters validates the circle and returns its radius in meters.
+func (c *StaticMapCircle) meters() (float64, error) {
+	if !c.Center.valid() {
+		return 0, fmt.Errorf("mapquest: invalid circle center %v", c.Center)
+	}
+	if c.Radius <= 0 || math.IsInf(c.Radius, 0) || math.IsNaN(c.Radius) {
+		return 0, fmt.Errorf("mapquest: invalid circle radius %v", c.Radius)
+	}
+	switch c.Unit {
+	case "", StaticMapRadiusMeters:
+		return c.Radius, nil
+	case StaticMapRadiusMiles:
+		return c.Radius * metersPerMile, nil
+	default:
+		return 0, fmt.Errorf("mapquest: invalid circle radius unit %q", c.Unit)
+	}
+}
+
+// StaticMapRectangle is a rectangle to draw on a map.
+type StaticMapRectangle struct {
+	// Box specifies two opposite corners of the rectangle.
+	Box GeoBox
+
+	// Color of the stroke as hex RGB, e.g. "FF0000". It is optional.
+	Color string
+
+	// Opacity of the stroke in the range of 0 (transparent) to 1
+	// (opaque). The default is opaque.
+	Opacity *float64
+
+	// Width of the stroke in pixels. It is optional.
+	Width int
+
+	// FillColor of the rectangle as hex RGB, e.g. "00FF00". It is optional.
+	FillColor string
+
+	// FillOpacity of the rectangle in the range of 0 (transparent) to 1
+	// (opaque). The default is opaque.
+	FillOpacity *float64
+}
+
+// param returns the rectangle in the format of the polygon parameter.
+func (r *StaticMapRectangle) param() (string, error) {
+	return r.shape().param(true)
+}
+
+// v5Param returns the rectangle in the format of the shape parameter
+// of version 5 of the API.
+func (r *StaticMapRectangle) v5Param() (string, error) {
+	return r.shape().v5Param(true)
+}
+
+// shape returns the rectangle as a polygon.
+func (r *StaticMapRectangle) shape() *StaticMapShape {
+	a, b := r.Box.A, r.Box.B
+	return &StaticMapShape{
+		Points: GeoLine{
+			a,
+			{Latitude: a.Latitude, Longitude: b.Longitude},
+			b,
+			{Latitude: b.Latitude, Longitude: a.Longitude},
+		},
+		Color:       r.Color,
+		Opacity:     r.Opacity,
+		Width:       r.Width,
+		FillColor:   r.FillColor,
+		FillOpacity: r.FillOpacity,
+	}
+}
+
+// staticMapStyle returns the styling parts of a shape parameter,
+// e.g. "color:0xFF0000" and "width:3". Version 5 of the API calls
+// the stroke color "border" and expects colors without 0x prefix.
+func staticMapStyle(color string, opacity *float64, width int, fillColor string, fillOpacity *float64, fill, v5 bool) ([]string, error) {
+	colorKey, colorPrefix := "color:", "0x"
+	if v5 {
+		colorKey, colorPrefix = "border:", ""
+	}
+	parts := make([]string, 0, 4)
+	if color != "" || opacity != nil {
+		c, err := staticMapColor(color, opacity)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, colorKey+colorPrefix+strings.TrimPrefix(c, "0x"))
+	}
+	if width < 0 {
+		return nil, fmt.Errorf("mapquest: invalid shape width %d", width)
+	}
+	if width > 0 {
+		parts = append(parts, fmt.Sprintf("width:%d", width))
+	}
+	if fill && (fillColor != "" || fillOpacity != nil) {
+		c, err := staticMapColor(fillColor, fillOpacity)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, "fill:"+colorPrefix+strings.TrimPrefix(c, "0x"))
+	}
+	return parts, nil
+}
+
+// staticMapCoords returns the points as a comma-separated list
+// of lat,lng pairs.
+func staticMapCoords(points GeoLine) (string, error) {
+	coords := make([]string, len(points))
+	for i, pt := range points {
+		if !pt.valid() {
+			return "", fmt.Errorf("mapquest: invalid point %v in shape", pt)
+		}
+		coords[i] = fmt.Sprintf("%f,%f", pt.Latitude, pt.Longitude)
+	}
+	return strings.Join(coords, ","), nil
+}
+
+// staticMapColor returns color and opacity in the format expected by
+// the static map API, i.e. 0xRRGGBB or 0xAARRGGBB. The color defaults
+// to black if only an opacity is specified.
+func staticMapColor(color string, opacity *float64) (string, error) {
+	if color == "" {
+		color = "000000"
+	}
+	rgb, err := staticMapRGB(color)
+	if err != nil {
+		return "", err
+	}
+	if opacity == nil {
+		return "0x" + rgb, nil
+	}
+	if *opacity < 0 || *opacity > 1 {
+		return "", fmt.Errorf("mapquest: invalid opacity %v; expected a value between 0 and 1", *opacity)
+	}
+	return fmt.Sprintf("0x%02X%s", int(math.Round(*opacity*255)), rgb), nil
 }
 
 // PointOfInterest defines an interesting point to be displayed on a map.
@@ -174,3 +776,104 @@ type PointOfInterest struct {
 	// OffsetY is the offset on the y axis. It is optional.
 	OffsetY int
 }
+
+// staticMapRGB validates a hex RGB color like "FF0000", "#ff0000"
+// or "0xFF0000" and returns it as upper-case RRGGBB.
+func staticMapRGB(color string) (string, error) {
+	rgb := strings.TrimPrefix(strings.TrimPrefix(color, "#"), "0x")
+	if len(rgb) != 6 {
+		return "", fmt.Errorf("mapquest: invalid color %q; expected hex RGB like FF0000", color)
+	}
+	if _, err := strconv.ParseUint(rgb, 16, 32); err != nil {
+		return "", fmt.Errorf("mapquest: invalid color %q; expected hex RGB like FF0000", color)
+	}
+	return strings.ToUpper(rgb), nil
+}
+
+// StaticMapMarker is a marker to display on the map. Unlike
+// PointOfInterest, which only supports predefined icons, markers
+// can be styled and labeled, or use a custom icon.
+type StaticMapMarker struct {
+	// Location of the marker.
+	Location GeoPoint
+
+	// Shape of the marker, e.g. MarkerShapeMarker (the default),
+	// MarkerShapeCircle, MarkerShapeFlag or MarkerShapeVia.
+	Shape string
+
+	// Size of the marker, e.g. MarkerSizeSmall, MarkerSizeMedium
+	// or MarkerSizeLarge. It is optional.
+	Size string
+
+	// Color of the marker as hex RGB, e.g. "FF0000". It is optional.
+	Color string
+
+	// LabelColor is the color of the label as hex RGB. It is optional
+	// and requires Color to be set.
+	LabelColor string
+
+	// Label is a text displayed on the marker, e.g. "A" or "1".
+	// Flags support longer labels. It is optional.
+	Label string
+
+	// IconURL is the URL of a custom icon. If specified, Shape, Size,
+	// Color, LabelColor and Label are ignored.
+	IconURL string
+
+	// ZIndex specifies the drawing order of markers. Markers with a
+	// higher ZIndex are drawn on top of markers with a lower ZIndex.
+	// Markers with the same ZIndex are drawn in order.
+	ZIndex int
+}
+
+// style returns the marker style, e.g. "marker-sm-FF0000-A".
+func (m *StaticMapMarker) style() (string, error) {
+	if m.IconURL != "" {
+		u, err := url.Parse(m.IconURL)
+		if err != nil || !u.IsAbs() {
+			return "", fmt.Errorf("mapquest: invalid marker icon URL %q", m.IconURL)
+		}
+		return url.QueryEscape(m.IconURL), nil
+	}
+
+	parts := make([]string, 0, 5)
+	switch m.Shape {
+	case "":
+		parts = append(parts, MarkerShapeMarker)
+	case MarkerShapeMarker, MarkerShapeCircle, MarkerShapeFlag, MarkerShapeVia:
+		parts = append(parts, m.Shape)
+	default:
+		return "", fmt.Errorf("mapquest: invalid marker shape %q", m.Shape)
+	}
+	switch m.Size {
+	case "":
+	case MarkerSizeSmall, MarkerSizeMedium, MarkerSizeLarge:
+		parts = append(parts, m.Size)
+	default:
+		return "", fmt.Errorf("mapquest: invalid marker size %q", m.Size)
+	}
+	if m.Color != "" {
+		rgb, err := staticMapRGB(m.Color)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, rgb)
+	}
+	if m.LabelColor != "" {
+		if m.Color == "" {
+			return "", fmt.Errorf("mapquest: marker label color requires a marker color")
+		}
+		rgb, err := staticMapRGB(m.LabelColor)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, rgb)
+	}
+	if m.Label != "" {
+		if strings.ContainsAny(m.Label, "-|") {
+			return "", fmt.Errorf("mapquest: invalid marker label %q", m.Label)
+		}
+		parts = append(parts, url.QueryEscape(m.Label))
+	}
+	return strings.Join(parts, "-"), nil
+}
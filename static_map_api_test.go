@@ -1,9 +1,13 @@
 package mapquest
 
 import (
-	_ "bytes"
-	_ "image/png"
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
 	_ "io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -31,6 +35,78 @@ func TestStaticMapBuildURLs(t *testing.T) {
 			},
 			URL: "http://open.mapquestapi.com/staticmap/v4/getmap?center=48.151313,11.541650&size=500,300&zoom=9&imagetype=png&key=" + testKey,
 		},
+		{
+			Request: &StaticMapRequest{
+				Width:  500,
+				Height: 300,
+				Lines: []*StaticMapShape{
+					{
+						Points: GeoLine{{48.1, 11.5}, {48.2, 11.6}},
+						Color:  "#ff0000",
+						Width:  3,
+					},
+				},
+				Polygons: []*StaticMapShape{
+					{
+						Points:      GeoLine{{48.1, 11.5}, {48.2, 11.6}, {48.1, 11.7}},
+						Color:       "0000FF",
+						Opacity:     floatPtr(1),
+						FillColor:   "00FF00",
+						FillOpacity: floatPtr(0.5),
+					},
+				},
+			},
+			URL: "http://open.mapquestapi.com/staticmap/v4/getmap?size=500,300&polyline=color:0xFF0000|width:3|48.100000,11.500000,48.200000,11.600000&polygon=color:0xFF0000FF|fill:0x8000FF00|48.100000,11.500000,48.200000,11.600000,48.100000,11.700000&key=" + testKey,
+		},
+		{
+			Request: &StaticMapRequest{
+				Width:  500,
+				Height: 300,
+				Rectangles: []*StaticMapRectangle{
+					{
+						Box:         GeoBox{A: GeoPoint{48.2, 11.5}, B: GeoPoint{48.1, 11.6}},
+						Color:       "FF0000",
+						FillColor:   "FF0000",
+						FillOpacity: floatPtr(0.2),
+					},
+				},
+				Circles: []*StaticMapCircle{
+					{
+						Center: GeoPoint{48, 11},
+						Radius: 1,
+						Unit:   StaticMapRadiusMiles,
+						Width:  2,
+					},
+				},
+			},
+			URL: "http://open.mapquestapi.com/staticmap/v4/getmap?size=500,300&polygon=color:0xFF0000|fill:0x33FF0000|48.200000,11.500000,48.200000,11.600000,48.100000,11.600000,48.100000,11.500000&ellipse=width:2|48.014473,10.978370,47.985527,11.021630&key=" + testKey,
+		},
+		{
+			Request: &StaticMapRequest{
+				Width:  500,
+				Height: 300,
+				Markers: []*StaticMapMarker{
+					{
+						Location: GeoPoint{48.1, 11.5},
+						Size:     MarkerSizeSmall,
+						Color:    "ff0000",
+						ZIndex:   1,
+					},
+					{
+						Location:   GeoPoint{48.2, 11.6},
+						Shape:      MarkerShapeFlag,
+						Color:      "FFFFFF",
+						LabelColor: "000000",
+						Label:      "Store 1",
+					},
+					{
+						Location: GeoPoint{48.3, 11.7},
+						IconURL:  "https://example.com/icon.png?v=1",
+					},
+				},
+			},
+			URL: "http://open.mapquestapi.com/staticmap/v5/map?size=500,300&locations=48.200000,11.600000|flag-FFFFFF-000000-Store+1||48.300000,11.700000|https%3A%2F%2Fexample.com%2Ficon.png%3Fv%3D1||48.100000,11.500000|marker-sm-FF0000&key=" + testKey,
+		},
 	}
 
 	client := NewClient(testKey)
@@ -45,6 +121,273 @@ func TestStaticMapBuildURLs(t *testing.T) {
 	}
 }
 
+func TestStaticMapBuildURLInvalidShapes(t *testing.T) {
+	client := NewClient("")
+	tests := []*StaticMapRequest{
+		{Lines: []*StaticMapShape{{Points: GeoLine{{48.1, 11.5}}}}},
+		{Polygons: []*StaticMapShape{{Points: GeoLine{{48.1, 11.5}, {48.2, 11.6}}}}},
+		{Lines: []*StaticMapShape{{Points: GeoLine{{48.1, 11.5}, {48.2, 11.6}}, Color: "red"}}},
+		{Lines: []*StaticMapShape{{Points: GeoLine{{48.1, 11.5}, {48.2, 11.6}}, Opacity: floatPtr(1.5)}}},
+		{Lines: []*StaticMapShape{{Points: GeoLine{{48.1, 11.5}, {148.2, 11.6}}}}},
+		{Circles: []*StaticMapCircle{{Center: GeoPoint{48, 11}}}},
+		{Circles: []*StaticMapCircle{{Center: GeoPoint{48, 11}, Radius: -1}}},
+		{Circles: []*StaticMapCircle{{Center: GeoPoint{48, 11}, Radius: 1, Unit: "km"}}},
+		{Circles: []*StaticMapCircle{{Center: GeoPoint{48, 11}, Radius: 1e7}}},
+		{Circles: []*StaticMapCircle{{Center: GeoPoint{48, 11}, Radius: 100, FillColor: "#12345"}}},
+		{Markers: []*StaticMapMarker{{Location: GeoPoint{48, 11}, Shape: "star"}}},
+		{Markers: []*StaticMapMarker{{Location: GeoPoint{48, 11}, Size: "xl"}}},
+		{Markers: []*StaticMapMarker{{Location: GeoPoint{48, 11}, LabelColor: "FFFFFF"}}},
+		{Markers: []*StaticMapMarker{{Location: GeoPoint{48, 11}, Label: "a|b"}}},
+		{Markers: []*StaticMapMarker{{Location: GeoPoint{48, 11}, IconURL: "icon.png"}}},
+		{Markers: []*StaticMapMarker{{Location: GeoPoint{148, 11}}}},
+		{Markers: []*StaticMapMarker{{Location: GeoPoint{48, 11}}}, PointsOfInterest: []*PointOfInterest{{Label: "mcenter"}}},
+		{Markers: []*StaticMapMarker{{Location: GeoPoint{48, 11}}}, Scale: 5},
+		{Markers: []*StaticMapMarker{{Location: GeoPoint{48, 11}}}, Lines: []*StaticMapShape{{Points: GeoLine{{48.1, 11.5}}}}},
+		{Markers: []*StaticMapMarker{{Location: GeoPoint{48, 11}}}, Circles: []*StaticMapCircle{{Center: GeoPoint{48, 11}}}},
+		{Rectangles: []*StaticMapRectangle{{Box: GeoBox{A: GeoPoint{48.2, 11.5}, B: GeoPoint{48.1, 11.6}}, Color: "GGGGGG"}}},
+	}
+	for i, req := range tests {
+		if _, err := client.StaticMap().buildURL(req); err == nil {
+			t.Errorf("#%d: expected error, got: nil", i)
+		}
+	}
+}
+
+func TestStaticMapEndpoints(t *testing.T) {
+	var path string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Header().Set("Content-Type", "image/png")
+		png.Encode(w, image.NewRGBA(image.Rect(0, 0, 1, 1)))
+	}))
+	defer ts.Close()
+
+	line := GeoLine{{Latitude: 48.1, Longitude: 11.5}, {Latitude: 48.2, Longitude: 11.6}}
+	tests := []struct {
+		Name    string
+		Request *StaticMapRequest
+		Path    string
+	}{
+		{
+			Name:    "center",
+			Request: &StaticMapRequest{Center: &GeoPoint{48.1, 11.5}, Zoom: 9},
+			Path:    "/staticmap/v4/getmap",
+		},
+		{
+			Name:    "points of interest",
+			Request: &StaticMapRequest{PointsOfInterest: []*PointOfInterest{{Label: "mcenter", Latitude: 48.1, Longitude: 11.5}}},
+			Path:    "/staticmap/v4/getmap",
+		},
+		{
+			Name:    "lines and polygons",
+			Request: &StaticMapRequest{Lines: []*StaticMapShape{{Points: line}}, Polygons: []*StaticMapShape{{Points: append(line, GeoPoint{48.1, 11.7})}}},
+			Path:    "/staticmap/v4/getmap",
+		},
+		{
+			Name:    "circles and rectangles",
+			Request: &StaticMapRequest{Circles: []*StaticMapCircle{{Center: GeoPoint{48.1, 11.5}, Radius: 100}}, Rectangles: []*StaticMapRectangle{{Box: GeoBox{A: line[0], B: line[1]}}}},
+			Path:    "/staticmap/v4/getmap",
+		},
+		{
+			Name:    "markers",
+			Request: &StaticMapRequest{Markers: []*StaticMapMarker{{Location: GeoPoint{48.1, 11.5}}}},
+			Path:    "/staticmap/v5/map",
+		},
+		{
+			Name:    "markers and line",
+			Request: &StaticMapRequest{Markers: []*StaticMapMarker{{Location: GeoPoint{48.1, 11.5}}}, Lines: []*StaticMapShape{{Points: line}}},
+			Path:    "/staticmap/v5/map",
+		},
+	}
+
+	client := newTestClient(t, ts)
+	for _, test := range tests {
+		test.Request.Width, test.Request.Height = 100, 100
+		if _, err := client.StaticMap().Get(test.Request); err != nil {
+			t.Fatalf("%s: expected no error, got: %v", test.Name, err)
+		}
+		if path != test.Path {
+			t.Errorf("%s: expected path %q, got: %q", test.Name, test.Path, path)
+		}
+	}
+}
+
+func TestStaticMapBuildV5URL(t *testing.T) {
+	client := NewClient("my-key")
+	got, err := client.StaticMap().buildURL(&StaticMapRequest{
+		Bestfit: &GeoBox{A: GeoPoint{48.1, 11.6}, B: GeoPoint{48.2, 11.5}},
+		Margin:  20,
+		Width:   500,
+		Height:  300,
+		Zoom:    9,
+		Type:    "hyb",
+		Format:  "png",
+		Markers: []*StaticMapMarker{{Location: GeoPoint{48.15, 11.55}, Color: "FF0000"}},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	expected := "http://open.mapquestapi.com/staticmap/v5/map?boundingBox=48.200000,11.500000,48.100000,11.600000&margin=20&size=500,300&zoom=9&type=hyb&format=png&locations=48.150000,11.550000|marker-FF0000&key=my-key"
+	if got != expected {
+		t.Errorf("expected %q, got: %q", expected, got)
+	}
+}
+
+func TestStaticMapBuildV5URLWithShapes(t *testing.T) {
+	client := NewClient("my-key")
+	got, err := client.StaticMap().buildURL(&StaticMapRequest{
+		Width:  300,
+		Height: 200,
+		Lines: []*StaticMapShape{
+			{
+				Points: GeoLine{{48.1, 11.5}, {48.2, 11.6}},
+				Color:  "0000FF",
+				Width:  4,
+			},
+		},
+		Polygons: []*StaticMapShape{
+			{
+				Points:      GeoLine{{48.1, 11.5}, {48.2, 11.6}, {48.1, 11.7}},
+				FillColor:   "00FF00",
+				FillOpacity: floatPtr(0.5),
+			},
+		},
+		Circles: []*StaticMapCircle{
+			{Center: GeoPoint{48.15, 11.55}, Radius: 1500, Color: "FF0000"},
+		},
+		Markers: []*StaticMapMarker{
+			{Location: GeoPoint{48.1, 11.5}, Size: MarkerSizeSmall, Color: "00FF00", Label: "A"},
+			{Location: GeoPoint{48.2, 11.6}, Size: MarkerSizeSmall, Color: "FF0000", Label: "B"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	expected := "http://open.mapquestapi.com/staticmap/v5/map?size=300,200" +
+		"&shape=border:0000FF|width:4|48.100000,11.500000|48.200000,11.600000" +
+		"&shape=fill:8000FF00|48.100000,11.500000|48.200000,11.600000|48.100000,11.700000|48.100000,11.500000" +
+		"&shape=border:FF0000|radius:1.5km|48.150000,11.550000" +
+		"&locations=48.100000,11.500000|marker-sm-00FF00-A||48.200000,11.600000|marker-sm-FF0000-B" +
+		"&key=my-key"
+	if got != expected {
+		t.Errorf("expected %q, got: %q", expected, got)
+	}
+}
+
+func TestStaticMapURL(t *testing.T) {
+	client := NewClient("my-key")
+	req := &StaticMapRequest{
+		Center: &GeoPoint{Latitude: 48.151313, Longitude: 11.54165},
+		Zoom:   9,
+		Width:  500,
+		Height: 300,
+	}
+	base := "http://open.mapquestapi.com/staticmap/v4/getmap?center=48.151313,11.541650&size=500,300&zoom=9"
+
+	got, err := client.StaticMap().URL(req)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if want := base + "&key=my-key"; got != want {
+		t.Errorf("expected %q, got: %q", want, got)
+	}
+
+	got, err = client.StaticMap().URLWithKey(req, "")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if got != base {
+		t.Errorf("expected %q, got: %q", base, got)
+	}
+
+	got, err = client.StaticMap().URLWithKey(req, "{{KEY}}")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if want := base + "&key={{KEY}}"; got != want {
+		t.Errorf("expected %q, got: %q", want, got)
+	}
+
+	if _, err := client.StaticMap().URL(&StaticMapRequest{Circles: []*StaticMapCircle{{}}}); err == nil {
+		t.Error("expected error, got: nil")
+	}
+}
+
+func TestStaticMapGetRaw(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 3))); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png; charset=binary")
+		w.Write(data)
+	}))
+	defer ts.Close()
+
+	client := newTestClient(t, ts)
+	req := &StaticMapRequest{Width: 4, Height: 3, Format: "png"}
+
+	raw, contentType, err := client.StaticMap().GetRaw(req)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if contentType != "image/png" {
+		t.Errorf("expected %q, got: %q", "image/png", contentType)
+	}
+	if !bytes.Equal(raw, data) {
+		t.Errorf("expected %d original bytes, got: %d", len(data), len(raw))
+	}
+
+	var out bytes.Buffer
+	contentType, err = client.StaticMap().Write(&out, req)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if contentType != "image/png" {
+		t.Errorf("expected %q, got: %q", "image/png", contentType)
+	}
+	if !bytes.Equal(out.Bytes(), data) {
+		t.Errorf("expected %d original bytes, got: %d", len(data), out.Len())
+	}
+
+	img, err := client.StaticMap().Get(req)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if got := img.Bounds().Dx(); got != 4 {
+		t.Errorf("expected width of %d, got: %d", 4, got)
+	}
+}
+
+func TestStaticMapGetUnexpectedContentType(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><body>Service unavailable</body></html>`))
+	}))
+	defer ts.Close()
+
+	client := newTestClient(t, ts)
+	req := &StaticMapRequest{Width: 4, Height: 3}
+
+	_, err := client.StaticMap().Get(req)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got: %v", err)
+	}
+	if apiErr.StatusCode != http.StatusOK {
+		t.Errorf("expected status %d, got: %d", http.StatusOK, apiErr.StatusCode)
+	}
+	if _, _, err := client.StaticMap().GetRaw(req); !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got: %v", err)
+	}
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
 func TestStaticMapGet(t *testing.T) {
 	key, err := readKey(t)
 	if err != nil {